@@ -0,0 +1,81 @@
+package log
+
+import (
+	"time"
+)
+
+// A Budget records the cumulative time spent in named phases, typically over the course of a single request.
+// It is meant to be logged once, when the request ends:
+//
+//	var b Budget
+//	b.Start("db")
+//	...
+//	b.Stop("db")
+//	...
+//	Log(Info, "request done", &b)
+//
+// A phase may be started and stopped any number of times, its durations adding up.
+//
+// The zero value is ready for use. A Budget is not concurrent safe, as it is expected to be used from a single goroutine.
+type Budget struct {
+	phases []budgetPhase // in order of first Start
+	index  map[string]int
+}
+
+// Entries returns {"phases": {phase: duration, ...}} followed by {"total": duration}, where total is the sum of all phases.
+// Phases that are still running only contribute their already completed durations.
+//
+// The returned Entries are a snapshot and will not change along with the Budget.
+func (x *Budget) Entries() Entries {
+	phases := make(Entries, len(x.phases))
+	var total time.Duration
+	for i, p := range x.phases {
+		phases[i] = Entry{p.name, p.d}
+		total += p.d
+	}
+
+	return Entries{
+		{"phases", phases},
+		{"total", total},
+	}
+}
+
+// Start marks the beginning of a phase. Starting an already running phase has no effect.
+func (x *Budget) Start(phase string) {
+	i, ok := x.index[phase]
+	if !ok {
+		if x.index == nil {
+			x.index = make(map[string]int)
+		}
+		i = len(x.phases)
+		x.index[phase] = i
+		x.phases = append(x.phases, budgetPhase{name: phase})
+	}
+
+	p := &x.phases[i]
+	if !p.start.IsZero() {
+		return
+	}
+	p.start = time.Now()
+}
+
+// Stop marks the end of a phase, adding the elapsed time since the matching Start. Stopping a phase that is not running has no effect.
+func (x *Budget) Stop(phase string) {
+	i, ok := x.index[phase]
+	if !ok {
+		return
+	}
+
+	p := &x.phases[i]
+	if p.start.IsZero() {
+		return
+	}
+	p.d += time.Since(p.start)
+	p.start = time.Time{}
+}
+
+type budgetPhase struct {
+	name  string
+	d     time.Duration // accumulated
+	start time.Time     // zero if not running
+}
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	var b Budget
+	b.Start("db")
+	time.Sleep(2 * time.Millisecond)
+	b.Stop("db")
+	b.Start("render")
+	b.Stop("render")
+	b.Start("db")
+	time.Sleep(2 * time.Millisecond)
+	b.Stop("db")
+	b.Stop("missing") // no effect
+
+	e := b.Entries()
+	if len(e) != 2 || e[0].Key != "phases" || e[1].Key != "total" {
+		t.Fatalf("unexpected layout: %v", e)
+	}
+
+	phases := e[0].Value.(Entries)
+	if len(phases) != 2 || phases[0].Key != "db" || phases[1].Key != "render" {
+		t.Fatalf("phases not in order of first start: %v", phases)
+	}
+
+	db := phases[0].Value.(time.Duration)
+	render := phases[1].Value.(time.Duration)
+	if db < 4*time.Millisecond {
+		t.Fatalf("db durations not accumulated: %v", db)
+	}
+	if total := e[1].Value.(time.Duration); total != db+render {
+		t.Fatalf("expected total %v, got %v", db+render, total)
+	}
+}
+
+func TestBudgetSnapshot(t *testing.T) {
+	var b Budget
+	b.Start("a")
+	b.Stop("a")
+	e := b.Entries()
+	before := e[1].Value
+
+	b.Start("a")
+	time.Sleep(time.Millisecond)
+	b.Stop("a")
+	if e[1].Value != before {
+		t.Fatal("returned Entries changed along with the Budget")
+	}
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"sync"
+	"time"
+
+	"github.com/blitz-frost/log/logger"
+)
+
+// BufferedKey is the key of the Entry holding the original timestamp of replayed logs, for backends that are not TimedLoggers.
+const BufferedKey = "bufferedAt"
+
+// A TimedLogger can log with an explicit timestamp.
+type TimedLogger interface {
+	LogAt(t time.Time, lvl int, msg string, e ...EntriesGiver)
+}
+
+// A BufferingLogger holds logs in memory until the actual Logger becomes available, for example during program initialization.
+// Once SetBackend is called, buffered logs are replayed in order, and subsequent logs are forwarded directly.
+//
+// Logs are timestamped when they are buffered. On replay, the timestamp is passed on to backends that are TimedLoggers (such as all logger.T based Loggers in this module).
+// Other backends receive it as an additional BufferedKey Entry.
+//
+// Concurrent safe.
+type BufferingLogger struct {
+	mux sync.RWMutex
+	dst Logger // nil until set
+
+	max     int
+	buf     []logger.Data
+	dropped int
+}
+
+// BufferingLoggerMake returns a BufferingLogger that holds at most max logs. Further logs are discarded until a backend is set.
+func BufferingLoggerMake(max int) *BufferingLogger {
+	return &BufferingLogger{
+		max: max,
+	}
+}
+
+// Close closes the backend, if it is a Closer. If no backend has been set, buffered logs are discarded.
+func (x *BufferingLogger) Close() {
+	x.mux.Lock()
+	defer x.mux.Unlock()
+
+	if x.dst == nil {
+		x.buf = nil
+		return
+	}
+	forwarder{x.dst}.Close()
+}
+
+func (x *BufferingLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	x.mux.RLock()
+	if dst := x.dst; dst != nil {
+		dst.Log(lvl, msg, e...)
+		x.mux.RUnlock()
+		return
+	}
+	x.mux.RUnlock()
+
+	x.mux.Lock()
+	defer x.mux.Unlock()
+
+	// backend might have been set in the meantime
+	if x.dst != nil {
+		x.dst.Log(lvl, msg, e...)
+		return
+	}
+
+	if len(x.buf) >= x.max {
+		x.dropped++
+		return
+	}
+
+	// gather entries synchronously
+	s := make([]Entries, len(e))
+	for i := range e {
+		s[i] = logger.Snapshot(e[i].Entries())
+	}
+	x.buf = append(x.buf, logger.Data{
+		Time:    time.Now(),
+		Level:   lvl,
+		Message: msg,
+		Entries: s,
+	})
+}
+
+// Preformat forwards to the backend if it has been set and is a Preformatter. Otherwise returns the input unchanged.
+func (x *BufferingLogger) Preformat(e EntriesGiver) EntriesGiver {
+	x.mux.RLock()
+	defer x.mux.RUnlock()
+
+	if p, ok := x.dst.(Preformatter); ok {
+		return p.Preformat(e)
+	}
+	return e
+}
+
+// SetBackend replays all buffered logs to dst, then forwards all future logs to it.
+// If any logs were discarded due to the buffer limit, a Warning reporting their number is logged after the replay.
+//
+// Logs issued during the replay wait for it to complete, so order is preserved. Subsequent calls have no effect.
+func (x *BufferingLogger) SetBackend(dst Logger) {
+	x.mux.Lock()
+	defer x.mux.Unlock()
+
+	if x.dst != nil {
+		return
+	}
+
+	for _, data := range x.buf {
+		givers := make([]EntriesGiver, len(data.Entries))
+		for i := range data.Entries {
+			givers[i] = data.Entries[i]
+		}
+		if t, ok := dst.(TimedLogger); ok {
+			t.LogAt(data.Time, data.Level, data.Message, givers...)
+		} else {
+			givers = append(givers, Entry{BufferedKey, data.Time})
+			dst.Log(data.Level, data.Message, givers...)
+		}
+	}
+	if x.dropped > 0 {
+		dst.Log(Warning, "log buffer overflow", Entry{"dropped", x.dropped})
+	}
+
+	x.dst = dst
+	x.buf = nil
+}
@@ -0,0 +1,73 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+// timedRecorder also records explicit timestamps
+type timedRecorder struct {
+	recorder
+	times []time.Time
+}
+
+func (x *timedRecorder) LogAt(t time.Time, lvl int, msg string, e ...EntriesGiver) {
+	x.times = append(x.times, t)
+	x.Log(lvl, msg, e...)
+}
+
+func TestBufferingLogger(t *testing.T) {
+	x := BufferingLoggerMake(3)
+	start := time.Now()
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		x.Log(Info, msg)
+	}
+
+	dst := &timedRecorder{}
+	x.SetBackend(dst)
+	x.Log(Info, "e")
+
+	var msgs []string
+	for _, r := range dst.records() {
+		msgs = append(msgs, r.msg)
+	}
+	expected := []string{"a", "b", "c", "log buffer overflow", "e"}
+	if len(msgs) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, msgs)
+	}
+	for i := range expected {
+		if msgs[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, msgs)
+		}
+	}
+
+	if len(dst.times) != 3 {
+		t.Fatalf("expected 3 timestamped replays, got %d", len(dst.times))
+	}
+	for i, tm := range dst.times {
+		if tm.Before(start) || (i > 0 && tm.Before(dst.times[i-1])) {
+			t.Fatalf("unexpected replay timestamps: %v", dst.times)
+		}
+	}
+	if v, _ := lookup(dst.records()[3].e, "dropped"); v != 1 {
+		t.Fatalf("expected 1 dropped log, got %v", v)
+	}
+}
+
+func TestBufferingLoggerUntimed(t *testing.T) {
+	x := BufferingLoggerMake(1)
+	before := time.Now()
+	x.Log(Info, "a", Entry{"k", 1})
+
+	dst := &recorder{}
+	x.SetBackend(dst)
+
+	e := dst.records()[0].e
+	if v, _ := lookup(e, "k"); v != 1 {
+		t.Fatalf("entries lost: %v", e)
+	}
+	v, ok := lookup(e, BufferedKey)
+	if tm, _ := v.(time.Time); !ok || tm.Before(before) {
+		t.Fatalf("missing original timestamp: %v", e)
+	}
+}
@@ -0,0 +1,85 @@
+package log
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ComponentKey is the key of the Entry added by ComponentLogger.
+const ComponentKey = "component"
+
+// A ComponentLogger attributes each log to the package that issued it, by appending {ComponentKey, [package import path]} to its Entries.
+// This gives coarse, automatic attribution without having to set up a Node for every component.
+//
+// The caller is found by walking the call stack from the Log call upwards, skipping frames that belong to this package (Node, LogError, other wrappers, etc.), then skipping an additional, configurable number of frames.
+// The latter is useful when logging through custom helper functions.
+//
+// Resolved components are cached per program counter, but walking the stack still has a cost on every call (runtime.Callers), so this is opt-in.
+type ComponentLogger struct {
+	forwarder
+	skip int
+}
+
+// ComponentMake returns a ComponentLogger that forwards to dst. skip is the number of additional caller frames to skip, typically 0.
+func ComponentMake(dst Logger, skip int) ComponentLogger {
+	return ComponentLogger{
+		forwarder: forwarder{dst},
+		skip:      skip,
+	}
+}
+
+func (x ComponentLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:]) // skip runtime.Callers and ComponentLogger.Log
+
+	skip := x.skip
+	for _, pc := range pcs[:n] {
+		c := componentOf(pc)
+		if c == ownPackage {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+
+		// don't modify the input slice
+		e = append(e[:len(e):len(e)], Entry{ComponentKey, c})
+		break
+	}
+
+	x.dst.Log(lvl, msg, e...)
+}
+
+// program counter -> package import path
+var componentCache sync.Map
+
+// import path of this package
+var ownPackage = func() string {
+	var pc [1]uintptr
+	runtime.Callers(1, pc[:])
+	return componentOf(pc[0])
+}()
+
+// componentOf returns the package import path of the function containing pc
+func componentOf(pc uintptr) string {
+	if c, ok := componentCache.Load(pc); ok {
+		return c.(string)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	c := packageOf(frame.Function)
+	componentCache.Store(pc, c)
+
+	return c
+}
+
+// packageOf extracts the package import path from a fully qualified function name, such as "example.com/a/b.(*T).Method.func1"
+func packageOf(fn string) string {
+	slash := strings.LastIndexByte(fn, '/') + 1
+	if dot := strings.IndexByte(fn[slash:], '.'); dot >= 0 {
+		return fn[:slash+dot]
+	}
+	return fn
+}
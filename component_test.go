@@ -0,0 +1,69 @@
+package log_test
+
+import (
+	"testing"
+
+	"github.com/blitz-frost/log"
+)
+
+// componentOf logs through x and returns the attached component
+func componentOf(t *testing.T, f func(log.Logger)) any {
+	t.Helper()
+
+	var got any
+	found := false
+	dst := loggerFunc(func(lvl int, msg string, e ...log.EntriesGiver) {
+		for _, g := range e {
+			for _, entry := range g.Entries() {
+				if entry.Key == log.ComponentKey {
+					got, found = entry.Value, true
+				}
+			}
+		}
+	})
+	f(dst)
+	if !found {
+		t.Fatal("no component attached")
+	}
+	return got
+}
+
+// loggerFunc adapts a function to the Logger interface
+type loggerFunc func(int, string, ...log.EntriesGiver)
+
+func (x loggerFunc) Log(lvl int, msg string, e ...log.EntriesGiver) {
+	x(lvl, msg, e...)
+}
+
+// helper logs on behalf of its caller
+//
+//go:noinline
+func helper(x log.Logger) {
+	x.Log(log.Info, "msg")
+}
+
+func TestComponentLogger(t *testing.T) {
+	const pkg = "github.com/blitz-frost/log_test"
+
+	// direct call, and through a Node, which belongs to the log package
+	c := componentOf(t, func(dst log.Logger) {
+		log.ComponentMake(dst, 0).Log(log.Info, "msg")
+	})
+	if c != pkg {
+		t.Fatalf("expected %q, got %v", pkg, c)
+	}
+	c = componentOf(t, func(dst log.Logger) {
+		log.NodeMake(log.ComponentMake(dst, 0), nil).Log(log.Info, "msg")
+	})
+	if c != pkg {
+		t.Fatalf("expected %q through a Node, got %v", pkg, c)
+	}
+
+	// skipping all test frames (helper, closure, componentOf, test function) lands in the testing package
+	c = componentOf(t, func(dst log.Logger) {
+		helper(log.ComponentMake(dst, 4))
+	})
+	if c != "testing" {
+		t.Fatalf("expected %q, got %v", "testing", c)
+	}
+}
@@ -0,0 +1,47 @@
+package log
+
+// A DeadLetterLogger validates logs before forwarding them. Valid logs go to the main destination, while invalid ones are diverted to a dead letter destination, for later inspection.
+// Useful for catching logging misuse (malformed structured data, missing mandatory keys, etc.) without polluting the main stream.
+//
+// Each EntriesGiver is gathered only once; the chosen destination receives the same Entries that were validated.
+// Preformatting is performed independently for both destinations, same as MultiLogger, and is retained for EntriesGivers preformatted by the DeadLetterLogger itself.
+// Closing closes both destinations, if they are Closers.
+type DeadLetterLogger struct {
+	MultiLogger // main, dead
+
+	valid DeadLetterPredicate
+}
+
+// A DeadLetterPredicate checks if a log is valid. Receives the gathered Entries of each EntriesGiver, in order.
+// Must not modify its input, and must be concurrent safe.
+type DeadLetterPredicate func(lvl int, msg string, e []Entries) bool
+
+// DeadLetterMake returns a DeadLetterLogger that forwards logs that satisfy valid to main, and the rest to dead.
+func DeadLetterMake(main, dead Logger, valid DeadLetterPredicate) DeadLetterLogger {
+	return DeadLetterLogger{
+		MultiLogger: MultiLoggerMake(main, dead),
+		valid:       valid,
+	}
+}
+
+func (x DeadLetterLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	s := make([]Entries, len(e))
+	givers := make([]EntriesGiver, len(e))
+	for i, g := range e {
+		s[i] = g.Entries()
+
+		// forward what was validated, so dynamic EntriesGivers can't change in between
+		// own preformatted values are immutable, and are kept for their destination specific form
+		if pre, ok := g.(multiEntries); ok && x.owns(pre) {
+			givers[i] = g
+		} else {
+			givers[i] = s[i]
+		}
+	}
+
+	if x.valid(lvl, msg, s) {
+		x.forward(0, lvl, msg, givers)
+	} else {
+		x.forward(1, lvl, msg, givers)
+	}
+}
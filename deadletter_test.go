@@ -0,0 +1,67 @@
+package log
+
+import (
+	"testing"
+)
+
+func TestDeadLetterLogger(t *testing.T) {
+	main, dead := &recorder{}, &recorder{}
+	hasID := func(lvl int, msg string, e []Entries) bool {
+		for _, s := range e {
+			if _, ok := lookup(s, "id"); ok {
+				return true
+			}
+		}
+		return false
+	}
+	x := DeadLetterMake(main, dead, hasID)
+
+	n := NodeMake(x, Entries{{"service", "api"}})
+	n.Log(Info, "valid", Entry{"id", 1})
+	n.Log(Info, "invalid")
+	x.Close()
+
+	if r := main.records(); len(r) != 1 || r[0].msg != "valid" || !entriesEqual(r[0].e, Entries{{"service", "api"}, {"id", 1}}) {
+		t.Fatalf("unexpected main logs: %v", r)
+	}
+	if r := dead.records(); len(r) != 1 || r[0].msg != "invalid" || !entriesEqual(r[0].e, Entries{{"service", "api"}}) {
+		t.Fatalf("unexpected dead letter logs: %v", r)
+	}
+	if main.closed != 1 || dead.closed != 1 {
+		t.Fatal("destinations not closed")
+	}
+}
+
+// flipGiver alternates between returning an id and not, on each call
+type flipGiver struct {
+	calls *int
+}
+
+func (x flipGiver) Entries() Entries {
+	*x.calls++
+	if *x.calls%2 == 1 {
+		return Entries{{"id", 1}}
+	}
+	return Entries{{"other", 2}}
+}
+
+func TestDeadLetterLoggerGatherOnce(t *testing.T) {
+	main, dead := &recorder{}, &recorder{}
+	x := DeadLetterMake(main, dead, func(lvl int, msg string, e []Entries) bool {
+		_, ok := lookup(e[0], "id")
+		return ok
+	})
+
+	var calls int
+	x.Log(Info, "msg", flipGiver{&calls})
+
+	if calls != 1 {
+		t.Fatalf("EntriesGiver gathered %d times", calls)
+	}
+	if r := main.records(); len(r) != 1 || !entriesEqual(r[0].e, Entries{{"id", 1}}) {
+		t.Fatalf("main didn't receive the validated entries: %v", r)
+	}
+	if r := dead.records(); len(r) != 0 {
+		t.Fatalf("unexpected dead letter logs: %v", r)
+	}
+}
@@ -0,0 +1,93 @@
+package log
+
+import (
+	"fmt"
+)
+
+// An ExemplarLogger counts logs of a minimum level (Error by default) through a metrics callback, attaching the trace id found in the log's Entries as an OpenMetrics exemplar.
+// This links spikes in an error metric to specific traces.
+//
+// The trace id is taken from the first top level Entry whose key matches one of the setup keys, checked in order of key priority. Its value is converted using fmt.Sprint.
+// Logs without a trace id are still counted, with a nil exemplar.
+//
+// Forwards all logs to the destination, after counting. Counted logs are forwarded as the Entries gathered during the search, so each EntriesGiver is only gathered once.
+type ExemplarLogger struct {
+	forwarder
+	setup ExemplarSetup
+}
+
+// ExemplarSetup configures an ExemplarLogger. Count is mandatory.
+//
+// A Prometheus counter can be wired as:
+//
+//	Count: func(ex map[string]string) {
+//		counter.(prometheus.ExemplarAdder).AddWithExemplar(1, ex)
+//	}
+type ExemplarSetup struct {
+	Count    func(exemplar map[string]string) // increment the counter; must be concurrent safe
+	MinLevel int                              // defaults to Error
+	Keys     []string                         // trace id Entry keys; defaults to DefaultTraceKeys
+	Label    string                           // exemplar label name; defaults to "trace_id"
+}
+
+// DefaultTraceKeys are the Entry keys searched for trace ids by default.
+var DefaultTraceKeys = []string{"trace_id", "traceID", "trace"}
+
+// ExemplarMake returns an ExemplarLogger that forwards to dst.
+func ExemplarMake(dst Logger, setup ExemplarSetup) ExemplarLogger {
+	if setup.MinLevel == Default {
+		setup.MinLevel = Error
+	}
+	if len(setup.Keys) == 0 {
+		setup.Keys = DefaultTraceKeys
+	}
+	if setup.Label == "" {
+		setup.Label = "trace_id"
+	}
+
+	return ExemplarLogger{
+		forwarder: forwarder{dst},
+		setup:     setup,
+	}
+}
+
+func (x ExemplarLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	if lvl >= x.setup.MinLevel {
+		// gather once, so that dynamic EntriesGivers are not called again by the destination
+		gathered := make([]EntriesGiver, len(e))
+		for i, g := range e {
+			gathered[i] = g.Entries()
+		}
+		e = gathered
+
+		var exemplar map[string]string
+		if id, ok := x.traceID(e); ok {
+			exemplar = map[string]string{x.setup.Label: id}
+		}
+		x.setup.Count(exemplar)
+	}
+
+	x.dst.Log(lvl, msg, e...)
+}
+
+// traceID searches for the highest priority trace id key
+func (x ExemplarLogger) traceID(e []EntriesGiver) (string, bool) {
+	best := len(x.setup.Keys)
+	var val any
+	for _, g := range e {
+		for _, entry := range g.Entries() {
+			for i := 0; i < best; i++ {
+				if entry.Key == x.setup.Keys[i] {
+					best = i
+					val = entry.Value
+					break
+				}
+			}
+		}
+	}
+
+	if best == len(x.setup.Keys) {
+		return "", false
+	}
+	return fmt.Sprint(val), true
+}
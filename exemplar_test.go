@@ -0,0 +1,58 @@
+package log
+
+import (
+	"testing"
+)
+
+// fakeCounter collects exemplars, like a Prometheus counter would
+type fakeCounter struct {
+	exemplars []map[string]string
+}
+
+func (x *fakeCounter) count(ex map[string]string) {
+	x.exemplars = append(x.exemplars, ex)
+}
+
+func TestExemplarLogger(t *testing.T) {
+	var c fakeCounter
+	dst := &recorder{}
+	x := ExemplarMake(dst, ExemplarSetup{Count: c.count})
+
+	var calls int
+	dynamic := countingGiver{Entries{{"trace", "low"}, {"trace_id", "abc"}}, &calls}
+	x.Log(Error, "fail", dynamic)
+	x.Log(Critical, "fail")
+	x.Log(Info, "ok", Entry{"trace_id", "ignored"})
+
+	if len(c.exemplars) != 2 {
+		t.Fatalf("expected 2 counts, got %d", len(c.exemplars))
+	}
+	if ex := c.exemplars[0]; len(ex) != 1 || ex["trace_id"] != "abc" {
+		t.Fatalf("unexpected exemplar: %v", ex)
+	}
+	if c.exemplars[1] != nil {
+		t.Fatalf("expected nil exemplar, got %v", c.exemplars[1])
+	}
+
+	if n := len(dst.records()); n != 3 {
+		t.Fatalf("expected 3 forwarded logs, got %d", n)
+	}
+	if calls != 1 {
+		t.Fatalf("EntriesGiver gathered %d times", calls)
+	}
+}
+
+func TestExemplarLoggerSetup(t *testing.T) {
+	var c fakeCounter
+	x := ExemplarMake(&recorder{}, ExemplarSetup{
+		Count:    c.count,
+		MinLevel: Warning,
+		Keys:     []string{"span"},
+		Label:    "span_id",
+	})
+
+	x.Log(Warning, "slow", Entry{"span", 42})
+	if len(c.exemplars) != 1 || c.exemplars[0]["span_id"] != "42" {
+		t.Fatalf("unexpected exemplars: %v", c.exemplars)
+	}
+}
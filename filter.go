@@ -0,0 +1,23 @@
+package log
+
+// A FilterLogger forwards only logs of a minimum level to its destination; all others are discarded.
+// Default level logs are treated as the lowest level.
+type FilterLogger struct {
+	forwarder
+	min int
+}
+
+// FilterMake returns a FilterLogger that forwards logs of at least level min to dst.
+func FilterMake(dst Logger, min int) FilterLogger {
+	return FilterLogger{
+		forwarder: forwarder{dst},
+		min:       min,
+	}
+}
+
+func (x FilterLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	if lvl < x.min {
+		return
+	}
+	x.dst.Log(lvl, msg, e...)
+}
@@ -3,6 +3,7 @@ package gcp
 import (
 	"context"
 	"encoding/json"
+	"sync"
 
 	"cloud.google.com/go/logging"
 	"github.com/blitz-frost/log"
@@ -12,52 +13,113 @@ import (
 
 // Logger wraps a GCP logging.Logger. Values must be created using LoggerMake or LoggerOf.
 //
-// Handles JSON marshaling and inserts encountered errors in the produced log (values starting in "LOG ERROR").
+// Handles JSON marshaling. Entries that fail to marshal are replaced in the produced log by a log.FormatErrorKey block:
+//
+//	"_logError": {"key": <entry key>, "error": <marshal error>}
 //
 // When closing, the underlying Logger will always be flushed before executing any custom Close function.
 type Logger struct {
 	logger.T[logging.Entry]
+
+	core *core
 }
 
 // LoggerMake creates a Logger value. It is a shorthand for logging.NewClient -> Client.Logger -> MakeLoggerOf.
 //
 // If the provided OnClose method is nil, it will default to closing the created Client.
+//
+// Loggers created this way can be refreshed.
 func LoggerMake(setup LoggerSetup) (Logger, error) {
 	if setup.Ctx == nil {
 		setup.Ctx = context.Background()
 	}
+	if setup.NewClient == nil {
+		setup.NewClient = logging.NewClient
+	}
 
-	cli, err := logging.NewClient(setup.Ctx, setup.Parent, setup.ClientOptions...)
+	cli, dst, err := setup.open()
 	if err != nil {
-		return Logger{}, log.ErrorMake("new GCP client", err)
+		return Logger{}, err
 	}
 
-	dst := cli.Logger(setup.LogID, setup.LoggerOptions...)
-
+	x := LoggerOf(dst, setup.OnClose, setup.Options...)
+	x.core.cli = cli
+	x.core.setup = &setup
 	if setup.OnClose == nil {
-		setup.OnClose = func() {
-			if err := cli.Close(); err != nil {
-				panic(err)
+		x.core.onClose = func() {
+			// the client might have been refreshed
+			x.core.mux.RLock()
+			defer x.core.mux.RUnlock()
+
+			if err := x.core.cli.Close(); err != nil {
+				x.core.onError.Handle(err)
 			}
 		}
 	}
 
-	return LoggerOf(dst, setup.OnClose), nil
+	return x, nil
 }
 
 // LoggerOf wraps a logging.Logger. Useful for custom setups.
 // onClose may be nil, in which case it will simply NoOp (the source logging.Client is unknown).
-func LoggerOf(dst *logging.Logger, onClose func()) Logger {
-	return Logger{logger.Make[logging.Entry](core{
+//
+// opts are passed on to logger.Make.
+func LoggerOf(dst *logging.Logger, onClose func(), opts ...logger.Option) Logger {
+	c := &core{
 		dst:     dst,
 		onClose: onClose,
-	})}
+	}
+	return Logger{
+		T:    logger.Make[logging.Entry](c, opts...),
+		core: c,
+	}
 }
 
 func (x Logger) Preformat(e log.EntriesGiver) log.EntriesGiver {
 	return entriesMake(e)
 }
 
+// Refresh replaces the underlying client and logging.Logger with new ones, created using the original setup (credential rotation, stale connections, etc.).
+//
+// The handoff goes as follows:
+//   - a new client and logging.Logger are created; on failure, the old ones remain in use
+//   - the new logging.Logger is swapped in; logs that are still queued will be written to it
+//   - the old logging.Logger is flushed, and the old client closed
+//
+// Only Loggers created by LoggerMake can be refreshed. Concurrent safe.
+func (x Logger) Refresh() error {
+	c := x.core
+	if c.setup == nil {
+		return log.ErrorMake("refresh GCP logger: unknown client setup", nil)
+	}
+
+	cli, dst, err := c.setup.open()
+	if err != nil {
+		return err
+	}
+
+	c.mux.Lock()
+	oldCli, oldDst := c.cli, c.dst
+	c.cli, c.dst = cli, dst
+	c.mux.Unlock()
+
+	if err := oldDst.Flush(); err != nil {
+		return log.ErrorMake("refresh GCP logger: flush", err)
+	}
+	if err := oldCli.Close(); err != nil {
+		return log.ErrorMake("refresh GCP logger: close client", err)
+	}
+
+	return nil
+}
+
+// SetOnError sets a function to handle flush and client close errors, instead of panicking.
+//
+// Should be called before the Logger is put to use.
+func (x Logger) SetOnError(f func(error)) {
+	x.core.onError = f
+}
+
 // Used by MakeLogger. Only Parent and LogID are mandatory.
 // See https://pkg.go.dev/cloud.google.com/go/logging (NewClient and Client.NewLogger) for more details.
 type LoggerSetup struct {
@@ -67,6 +129,40 @@ type LoggerSetup struct {
 	ClientOptions []option.ClientOption
 	LoggerOptions []logging.LoggerOption
 	OnClose       func()
+	Options       []logger.Option // passed on to logger.Make
+
+	// used to create clients, including on Refresh; defaults to logging.NewClient
+	NewClient func(ctx context.Context, parent string, opts ...option.ClientOption) (*logging.Client, error)
+}
+
+// open creates a new client and logging.Logger
+func (x *LoggerSetup) open() (*logging.Client, *logging.Logger, error) {
+	cli, err := x.NewClient(x.Ctx, x.Parent, x.ClientOptions...)
+	if err != nil {
+		return nil, nil, log.ErrorMake("new GCP client", err)
+	}
+
+	return cli, cli.Logger(x.LogID, x.LoggerOptions...), nil
+}
+
+// registered level to severity mappings
+var (
+	severities  map[int]logging.Severity
+	severityMux sync.RWMutex
+)
+
+// RegisterSeverity maps a log level to a GCP severity. Meant for custom levels, which would otherwise be logged with the Default severity.
+// Can also be used to override the mapping of predefined levels.
+//
+// Concurrent safe, but should typically be called during init.
+func RegisterSeverity(lvl int, sev logging.Severity) {
+	severityMux.Lock()
+	defer severityMux.Unlock()
+
+	if severities == nil {
+		severities = make(map[int]logging.Severity)
+	}
+	severities[lvl] = sev
 }
 
 type buffer []byte
@@ -89,30 +185,78 @@ func (x *buffer) append(e log.EntriesGiver) {
 }
 
 func (x *buffer) appendEntry(e log.Entry) {
-	m, _ := json.Marshal(e.Key) // might need escaping; marshalling a string never fails
+	n := len(*x)
+	x.appendKey(e.Key)
+	if err := x.appendValue(e.Key, e.Value); err != nil {
+		// not worth panicking over
+		// replace the whole entry with a structured error block
+		*x = (*x)[:n]
+		x.appendKey(log.FormatErrorKey)
+		x.appendError(e.Key, err)
+	}
+	*x = append(*x, ',')
+}
+
+func (x *buffer) appendError(key string, err error) {
+	x.start()
+	x.appendEntry(log.Entry{"key", key})
+	x.appendEntry(log.Entry{"error", err.Error()})
+	x.end()
+}
+
+func (x *buffer) appendKey(k string) {
+	m, _ := json.Marshal(k) // might need escaping; marshalling a string never fails
 	*x = append(*x, m...)
 	*x = append(*x, ':')
+}
+
+// appendList appends values as an array
+// elements that fail to marshal are replaced with a structured error block, referencing the key of the whole array
+func (x *buffer) appendList(key string, values []any) {
+	*x = append(*x, '[')
+	for _, v := range values {
+		n := len(*x)
+		if err := x.appendValue(key, v); err != nil {
+			*x = (*x)[:n]
+			x.start()
+			x.appendKey(log.FormatErrorKey)
+			x.appendError(key, err)
+			*x = append(*x, ',')
+			x.end()
+		}
+		*x = append(*x, ',')
+	}
+
+	n := len(*x) - 1
+	if (*x)[n] == ',' {
+		(*x)[n] = ']'
+	} else {
+		// empty array
+		*x = append(*x, ']')
+	}
+}
 
-	switch sub := e.Value.(type) {
+// appendValue appends a single value. Nothing is appended if marshaling fails.
+func (x *buffer) appendValue(key string, v any) error {
+	switch sub := v.(type) {
 	case log.EntriesGiver:
 		x.start()
 		x.append(sub)
 		x.end()
+	case logger.List:
+		x.appendList(key, sub)
 	case error:
 		// json marshal might produce nonsense
-		m, _ = json.Marshal(sub.Error())
+		m, _ := json.Marshal(sub.Error())
 		*x = append(*x, m...)
 	default:
 		m, err := json.Marshal(sub)
 		if err != nil {
-			// not worth panicking over
-			// replace the value with the json marshalling error
-			s := "LOG ERROR: " + err.Error()
-			m, _ = json.Marshal(s)
+			return err
 		}
 		*x = append(*x, m...)
 	}
-	*x = append(*x, ',')
+	return nil
 }
 
 // end an object
@@ -138,13 +282,21 @@ func (x *buffer) start() {
 }
 
 type core struct {
-	dst     *logging.Logger
+	mux sync.RWMutex // guards cli and dst against Refresh
+	cli *logging.Client
+	dst *logging.Logger
+
+	setup   *LoggerSetup // nil if the client is unknown
 	onClose func()
+	onError logger.ErrorHandler
 }
 
-func (x core) Close() {
-	if err := x.dst.Flush(); err != nil {
-		panic(err)
+func (x *core) Close() {
+	x.mux.RLock()
+	err := x.dst.Flush()
+	x.mux.RUnlock()
+	if err != nil {
+		x.onError.Handle(err)
 	}
 
 	if x.onClose != nil {
@@ -152,28 +304,33 @@ func (x core) Close() {
 	}
 }
 
-func (x core) Format(data logger.Data) logging.Entry {
-	// map level to gcp severity; seems to be 100 * lvl, but a switch is safer
-	var sev logging.Severity
-	switch data.Level {
-	case log.Default:
-		sev = logging.Default
-	case log.Debug:
-		sev = logging.Debug
-	case log.Info:
-		sev = logging.Info
-	case log.Notice:
-		sev = logging.Notice
-	case log.Warning:
-		sev = logging.Warning
-	case log.Error:
-		sev = logging.Error
-	case log.Critical:
-		sev = logging.Critical
-	case log.Alert:
-		sev = logging.Alert
-	case log.Emergency:
-		sev = logging.Emergency
+func (x *core) Format(data logger.Data) logging.Entry {
+	// registered mappings take precedence
+	severityMux.RLock()
+	sev, ok := severities[data.Level]
+	severityMux.RUnlock()
+	if !ok {
+		// map level to gcp severity; seems to be 100 * lvl, but a switch is safer
+		switch data.Level {
+		case log.Default:
+			sev = logging.Default
+		case log.Debug:
+			sev = logging.Debug
+		case log.Info:
+			sev = logging.Info
+		case log.Notice:
+			sev = logging.Notice
+		case log.Warning:
+			sev = logging.Warning
+		case log.Error:
+			sev = logging.Error
+		case log.Critical:
+			sev = logging.Critical
+		case log.Alert:
+			sev = logging.Alert
+		case log.Emergency:
+			sev = logging.Emergency
+		}
 	}
 
 	buf := bufferNew()
@@ -181,18 +338,21 @@ func (x core) Format(data logger.Data) logging.Entry {
 	buf.start()
 	buf.append(log.Entry{"msg", data.Message})
 	for _, e := range data.Entries {
-		buf.append(e)
+		buf.append(log.FilterSuppressed(e))
 	}
 	buf.end()
 
 	return logging.Entry{
-		Severity: sev,
-		Payload:  json.RawMessage(*buf),
+		Timestamp: data.Time,
+		Severity:  sev,
+		Payload:   json.RawMessage(*buf),
 	}
 }
 
-func (x core) Write(e logging.Entry) {
+func (x *core) Write(e logging.Entry) {
+	x.mux.RLock()
 	x.dst.Log(e)
+	x.mux.RUnlock()
 }
 
 // entries is the optimized preformatted entries for Logger.
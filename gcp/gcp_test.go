@@ -0,0 +1,239 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/blitz-frost/log"
+	"github.com/blitz-frost/log/logger"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// format formats a log with a bare core, returning the decoded payload
+func format(t *testing.T, lvl int, e ...log.Entries) (logging.Entry, map[string]any) {
+	t.Helper()
+
+	c := &core{}
+	o := c.Format(logger.Data{
+		Time:    time.Now(),
+		Level:   lvl,
+		Message: "msg",
+		Entries: e,
+	})
+
+	var m map[string]any
+	if err := json.Unmarshal(o.Payload.(json.RawMessage), &m); err != nil {
+		t.Fatalf("invalid payload %s: %v", o.Payload, err)
+	}
+	return o, m
+}
+
+func TestSecret(t *testing.T) {
+	const token = "hunter2"
+	e := log.Entries{{"token", log.Secret(token)}}
+
+	_, m := format(t, log.Info, e)
+	if v := m["token"]; v != log.Redacted {
+		t.Fatalf("expected %q, got %v", log.Redacted, v)
+	}
+
+	if pre := entriesMake(e); strings.Contains(string(pre.buf), token) {
+		t.Fatalf("secret leaked through preformatting: %s", pre.buf)
+	}
+}
+
+func TestMarshalError(t *testing.T) {
+	_, m := format(t, log.Info, log.Entries{
+		{"ok", 1},
+		{"bad", make(chan int)},
+		{"list", logger.List{2, make(chan int)}},
+	})
+
+	if m["ok"] != 1.0 {
+		t.Fatalf("valid entry lost: %v", m)
+	}
+
+	block, ok := m[log.FormatErrorKey].(map[string]any)
+	if !ok {
+		t.Fatalf("missing %s block: %v", log.FormatErrorKey, m)
+	}
+	if block["key"] != "bad" || block["error"] == "" {
+		t.Fatalf("unexpected %s block: %v", log.FormatErrorKey, block)
+	}
+
+	list, ok := m["list"].([]any)
+	if !ok || len(list) != 2 || list[0] != 2.0 {
+		t.Fatalf("unexpected list: %v", m["list"])
+	}
+	elem, ok := list[1].(map[string]any)
+	if _, found := elem[log.FormatErrorKey]; !ok || !found {
+		t.Fatalf("list element not replaced by an error block: %v", list[1])
+	}
+}
+
+// fakeFactory creates clients that never reach a real endpoint, counting calls
+type fakeFactory struct {
+	calls int
+	fail  error // returned instead of a client, if set
+}
+
+func (x *fakeFactory) NewClient(ctx context.Context, parent string, opts ...option.ClientOption) (*logging.Client, error) {
+	x.calls++
+	if x.fail != nil {
+		return nil, x.fail
+	}
+	opts = append(opts, option.WithoutAuthentication(), option.WithEndpoint("localhost:1"))
+	return logging.NewClient(ctx, parent, opts...)
+}
+
+func TestRefresh(t *testing.T) {
+	var f fakeFactory
+	x, err := LoggerMake(LoggerSetup{
+		Parent:    "projects/test",
+		LogID:     "test",
+		NewClient: f.NewClient,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer x.Close()
+
+	oldCli, oldDst := x.core.cli, x.core.dst
+	if err := x.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if f.calls != 2 {
+		t.Fatalf("expected 2 clients, got %d", f.calls)
+	}
+	if x.core.cli == oldCli || x.core.dst == oldDst {
+		t.Fatal("client not replaced")
+	}
+
+	// on failure, the current client remains in use
+	f.fail = errors.New("unavailable")
+	cli := x.core.cli
+	if err := x.Refresh(); !errors.Is(err, f.fail) {
+		t.Fatalf("expected factory error, got %v", err)
+	}
+	if x.core.cli != cli {
+		t.Fatal("client replaced despite failure")
+	}
+}
+
+// fakeServer is an in-process logging service that keeps the messages of received entries
+type fakeServer struct {
+	loggingpb.UnimplementedLoggingServiceV2Server
+
+	mux  sync.Mutex
+	msgs []string
+}
+
+func (x *fakeServer) WriteLogEntries(ctx context.Context, req *loggingpb.WriteLogEntriesRequest) (*loggingpb.WriteLogEntriesResponse, error) {
+	x.mux.Lock()
+	defer x.mux.Unlock()
+
+	for _, e := range req.Entries {
+		x.msgs = append(x.msgs, e.GetJsonPayload().GetFields()["msg"].GetStringValue())
+	}
+	return &loggingpb.WriteLogEntriesResponse{}, nil
+}
+
+func (x *fakeServer) received() []string {
+	x.mux.Lock()
+	defer x.mux.Unlock()
+	return append([]string(nil), x.msgs...)
+}
+
+// serve starts a fakeServer, returning a connection to it
+func serve(t *testing.T) (*fakeServer, *grpc.ClientConn) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	fake := &fakeServer{}
+	loggingpb.RegisterLoggingServiceV2Server(srv, fake)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return fake, conn
+}
+
+func TestRefreshInFlight(t *testing.T) {
+	oldSrv, oldConn := serve(t)
+	newSrv, newConn := serve(t)
+	conns := []*grpc.ClientConn{oldConn, newConn}
+
+	x, err := LoggerMake(LoggerSetup{
+		Parent: "projects/test",
+		LogID:  "test",
+		NewClient: func(ctx context.Context, parent string, opts ...option.ClientOption) (*logging.Client, error) {
+			conn := conns[0]
+			conns = conns[1:]
+			return logging.NewClient(ctx, parent, append(opts, option.WithGRPCConn(conn))...)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logRange := func(from, to int) {
+		for i := from; i < to; i++ {
+			x.Log(log.Info, strconv.Itoa(i))
+		}
+	}
+
+	// before, during and after the handoff
+	logRange(0, 100)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		logRange(100, 200)
+	}()
+	if err := x.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	logRange(200, 300)
+	x.Close()
+
+	seen := make(map[string]int)
+	for _, msg := range append(oldSrv.received(), newSrv.received()...) {
+		seen[msg]++
+	}
+	for i := 0; i < 300; i++ {
+		if n := seen[strconv.Itoa(i)]; n != 1 {
+			t.Fatalf("log %d received %d times", i, n)
+		}
+	}
+	if len(newSrv.received()) < 100 {
+		t.Fatal("logs after the handoff not written to the new logging.Logger")
+	}
+}
+
+func TestRefreshUnknownSetup(t *testing.T) {
+	x := LoggerOf(nil, nil)
+	if err := x.Refresh(); err == nil {
+		t.Fatal("expected error for a Logger without setup")
+	}
+}
@@ -6,6 +6,7 @@ require (
 	cloud.google.com/go/logging v1.9.0
 	github.com/blitz-frost/log v0.0.2
 	google.golang.org/api v0.169.0
+	google.golang.org/grpc v1.62.0
 )
 
 require (
@@ -38,6 +39,5 @@ require (
 	google.golang.org/genproto v0.0.0-20240205150955-31a09d347014 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20240205150955-31a09d347014 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240304161311-37d4d3c04a78 // indirect
-	google.golang.org/grpc v1.62.0 // indirect
 	google.golang.org/protobuf v1.32.0 // indirect
 )
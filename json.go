@@ -0,0 +1,375 @@
+package log
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/blitz-frost/log/logger"
+)
+
+// FormatErrorKey replaces the key of entries that could not be formatted by structured backends, such as JSONLogger:
+//
+//	"_logError": {"key": <entry key>, "error": <marshal error>}
+const FormatErrorKey = "_logError"
+
+// TruncatedKey marks the number of Entries dropped from an object by JSONLogger.SetMaxKeys.
+const TruncatedKey = "_truncated"
+
+// A JSONLogger writes logs to an io.Writer as one JSON object per record:
+//
+//	{"time":"2006-01-02T15:04:05.999999999Z07:00","level":"LEVEL","msg":"msg","key0":value0,"key1":{"subkey0":subvalue0}}
+//
+// Its purpose is to provide machine readable logs to local files or log shippers.
+// Error values are logged as their Error() string.
+type JSONLogger struct {
+	logger.T[[]byte]
+
+	core *jsonCore
+}
+
+// JSONLoggerMake returns a usable JSONLogger.
+// onClose may be nil, in which case it will default to closing the Writer, if it is also a io.Closer.
+//
+// opts are passed on to logger.Make.
+func JSONLoggerMake(dst io.Writer, onClose func(), opts ...logger.Option) JSONLogger {
+	c := &jsonCore{
+		w:         dst,
+		onClose:   onClose,
+		separator: []byte("\n"),
+	}
+	return JSONLogger{
+		T:    logger.Make[[]byte](c, opts...),
+		core: c,
+	}
+}
+
+func (x JSONLogger) Preformat(e EntriesGiver) EntriesGiver {
+	return jsonEntriesMake(e)
+}
+
+// SetGroup enables or disables the collapsing of consecutive top level Entries with the same key into a single array valued member:
+//
+//	{"item", 1}, {"item", 2}, {"other", 3}, {"item", 4} -> "item":[1,2],"other":3,"item":4
+//
+// Only directly consecutive Entries are grouped, so keys separated by others still result in multiple members. Subblocks are not affected.
+// Disabled by default.
+//
+// Should be called before the JSONLogger is put to use.
+func (x JSONLogger) SetGroup(on bool) {
+	x.core.group = on
+}
+
+// SetMaxKeys limits the number of members of every JSON object, at all nesting levels, to n (not counting the time, level and msg members).
+// This includes subblocks within lists, and maps with string keys, which are marshaled as objects. Struct values are marshaled as is.
+// Excess Entries are dropped, and replaced by a single {TruncatedKey: [number of dropped Entries]} member, so the output remains valid.
+// Top level Entries count as a single object, regardless of how many EntriesGivers they come from.
+//
+// n <= 0 disables the limit, which is the default. Note that enabling it bypasses preformatting.
+//
+// Should be called before the JSONLogger is put to use.
+func (x JSONLogger) SetMaxKeys(n int) {
+	x.core.maxKeys = n
+}
+
+// SetOnError sets a function to handle write and close errors, instead of panicking.
+// f will usually be called from the write goroutine; it must not block indefinitely, or log to the same JSONLogger.
+//
+// Should be called before the JSONLogger is put to use.
+func (x JSONLogger) SetOnError(f func(error)) {
+	x.core.onError = f
+}
+
+// SetSeparator changes the record separator, which is appended after each log. Defaults to "\n".
+//
+// Any occurrence of sep inside a record is replaced by its hex escaped form (\xHH for each byte), so that records can be safely split.
+// Note that control characters are always escaped by JSON encoding, so this only affects printable separators.
+//
+// Should be called before the JSONLogger is put to use.
+func (x JSONLogger) SetSeparator(sep string) {
+	x.core.separator = []byte(sep)
+}
+
+// jsonBuffer is the prefered formated block used by JSONLogger.
+type jsonBuffer []byte
+
+func (x *jsonBuffer) append(e EntriesGiver) {
+	// check for preformatted entries
+	if pre, ok := e.(jsonEntries); ok {
+		*x = append(*x, pre.buf...)
+		return
+	}
+
+	for _, entry := range e.Entries() {
+		x.appendEntry(entry)
+	}
+}
+
+func (x *jsonBuffer) appendEntry(e Entry) {
+	n := len(*x)
+	x.appendKey(e.Key)
+	if err := x.appendValue(e.Key, e.Value); err != nil {
+		// replace the whole entry with a structured error block
+		*x = (*x)[:n]
+		x.appendKey(FormatErrorKey)
+		x.appendFormatError(e.Key, err)
+	}
+	*x = append(*x, ',')
+}
+
+func (x *jsonBuffer) appendFormatError(key string, err error) {
+	x.start()
+	x.appendEntry(Entry{"key", key})
+	x.appendEntry(Entry{"error", err.Error()})
+	x.end()
+}
+
+// appendGroup appends multiple values of the same key as an array
+func (x *jsonBuffer) appendGroup(key string, values []any) {
+	x.appendKey(key)
+	x.appendList(key, values)
+	*x = append(*x, ',')
+}
+
+// appendGrouped appends top level entries, collapsing consecutive ones with the same key into arrays
+func (x *jsonBuffer) appendGrouped(data []Entries) {
+	var flat Entries
+	for _, e := range data {
+		flat = append(flat, e...)
+	}
+
+	for i := 0; i < len(flat); {
+		j := i + 1
+		for j < len(flat) && flat[j].Key == flat[i].Key {
+			j++
+		}
+
+		if j-i == 1 {
+			x.appendEntry(flat[i])
+		} else {
+			values := make([]any, j-i)
+			for k := range values {
+				values[k] = flat[i+k].Value
+			}
+			x.appendGroup(flat[i].Key, values)
+		}
+
+		i = j
+	}
+}
+
+func (x *jsonBuffer) appendKey(k string) {
+	m, _ := json.Marshal(k) // might need escaping; marshalling a string never fails
+	*x = append(*x, m...)
+	*x = append(*x, ':')
+}
+
+// appendList appends values as an array
+// elements that fail to marshal are replaced with a structured error block, referencing the key of the whole array
+func (x *jsonBuffer) appendList(key string, values []any) {
+	*x = append(*x, '[')
+	for _, v := range values {
+		n := len(*x)
+		if err := x.appendValue(key, v); err != nil {
+			*x = (*x)[:n]
+			x.start()
+			x.appendKey(FormatErrorKey)
+			x.appendFormatError(key, err)
+			*x = append(*x, ',')
+			x.end()
+		}
+		*x = append(*x, ',')
+	}
+
+	n := len(*x) - 1
+	if (*x)[n] == ',' {
+		(*x)[n] = ']'
+	} else {
+		// empty array
+		*x = append(*x, ']')
+	}
+}
+
+// appendValue appends a single value. Nothing is appended if marshaling fails.
+// key is only used for error reporting inside Lists.
+func (x *jsonBuffer) appendValue(key string, v any) error {
+	switch sub := v.(type) {
+	case EntriesGiver:
+		x.start()
+		x.append(sub)
+		x.end()
+	case logger.List:
+		x.appendList(key, sub)
+	case error:
+		// json marshal might produce nonsense
+		m, _ := json.Marshal(sub.Error())
+		*x = append(*x, m...)
+	default:
+		m, err := json.Marshal(sub)
+		if err != nil {
+			return err
+		}
+		*x = append(*x, m...)
+	}
+	return nil
+}
+
+// end an object
+func (x *jsonBuffer) end() {
+	n := len(*x) - 1
+	if (*x)[n] != '{' {
+		// appended objects should end in an unnecessary comma
+		(*x)[n] = '}'
+	} else {
+		// otherwise we are in an empty object; close it properly
+		*x = append(*x, '}')
+	}
+}
+
+// start a new object
+func (x *jsonBuffer) start() {
+	*x = append(*x, '{')
+}
+
+type jsonCore struct {
+	w       io.Writer
+	onClose func()
+	onError logger.ErrorHandler
+
+	separator []byte
+	group     bool
+	maxKeys   int
+}
+
+func (x *jsonCore) Close() {
+	if x.onClose != nil {
+		x.onClose()
+		return
+	}
+
+	if c, ok := x.w.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			x.onError.Handle(err)
+		}
+	}
+}
+
+func (x *jsonCore) Format(data logger.Data) []byte {
+	buf := make(jsonBuffer, 0, 1024)
+
+	buf.start()
+	buf.appendEntry(Entry{"time", data.Time.Format(time.RFC3339Nano)})
+	buf.appendEntry(Entry{"level", LevelString(data.Level)})
+	buf.appendEntry(Entry{"msg", data.Message})
+	entries := make([]Entries, len(data.Entries))
+	for i, e := range data.Entries {
+		entries[i] = FilterSuppressed(e)
+	}
+	if x.maxKeys > 0 {
+		var flat Entries
+		for _, e := range entries {
+			flat = append(flat, e...)
+		}
+		entries = []Entries{truncateEntries(flat, x.maxKeys)}
+	}
+	if x.group {
+		buf.appendGrouped(entries)
+	} else {
+		for _, e := range entries {
+			buf.append(e)
+		}
+	}
+	buf.end()
+
+	b := []byte(buf)
+	if len(x.separator) != 1 || x.separator[0] != '\n' {
+		b = escapeSeparator(b, x.separator)
+	}
+	return append(b, x.separator...)
+}
+
+func (x *jsonCore) Write(b []byte) {
+	if _, err := x.w.Write(b); err != nil {
+		x.onError.Handle(err)
+	}
+}
+
+// jsonEntries is the optimized preformated Entries for JSONLogger.
+type jsonEntries struct {
+	src Entries
+
+	buf jsonBuffer // holds comma separated json object members; ends in a comma
+}
+
+func jsonEntriesMake(src EntriesGiver) jsonEntries {
+	if same, ok := src.(jsonEntries); ok {
+		return same
+	}
+
+	var buf jsonBuffer
+	e := src.Entries()
+	for _, entry := range e {
+		buf.appendEntry(entry)
+	}
+
+	return jsonEntries{
+		src: e,
+		buf: buf,
+	}
+}
+
+func (x jsonEntries) Entries() Entries {
+	return x.src
+}
+
+// truncateEntries returns a copy of e, recursively limited to max Entries per block, with a trailing TruncatedKey Entry if anything was dropped.
+func truncateEntries(e Entries, max int) Entries {
+	n := len(e)
+	if n > max {
+		n = max
+	}
+
+	o := make(Entries, n, n+1)
+	for i := range o {
+		o[i] = Entry{e[i].Key, truncateValue(e[i].Value, max)}
+	}
+
+	if len(e) > max {
+		o = append(o, Entry{TruncatedKey, len(e) - max})
+	}
+	return o
+}
+
+// truncateValue returns v limited to max Entries per block, searching subblocks, lists and maps.
+// Maps with string keys would be marshaled as objects, so they are converted to Entries, sorted by key like encoding/json does.
+func truncateValue(v any, max int) any {
+	switch val := v.(type) {
+	case EntriesGiver:
+		return truncateEntries(val.Entries(), max)
+	case logger.List:
+		o := make(logger.List, len(val))
+		for i, elem := range val {
+			o[i] = truncateValue(elem, max)
+		}
+		return o
+	case string, bool, int, int64, uint64, float64, time.Time, time.Duration:
+		// skip reflection for common types
+		return v
+	}
+
+	r := reflect.ValueOf(v)
+	if r.Kind() != reflect.Map || r.Type().Key().Kind() != reflect.String || r.IsNil() {
+		return v
+	}
+
+	e := make(Entries, 0, r.Len())
+	for it := r.MapRange(); it.Next(); {
+		e = append(e, Entry{it.Key().String(), it.Value().Interface()})
+	}
+	sort.Slice(e, func(i, j int) bool {
+		return e[i].Key < e[j].Key
+	})
+	return truncateEntries(e, max)
+}
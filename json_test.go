@@ -0,0 +1,166 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/blitz-frost/log/logger"
+)
+
+// jsonOutput returns the output of a JSONLogger, after f is done using it
+func jsonOutput(f func(JSONLogger)) string {
+	var buf bytes.Buffer
+	x := JSONLoggerMake(&buf, func() {})
+	f(x)
+	x.Close()
+	return buf.String()
+}
+
+// jsonRecords decodes sep terminated JSON records
+func jsonRecords(t *testing.T, out, sep string) []map[string]any {
+	t.Helper()
+
+	if !strings.HasSuffix(out, sep) {
+		t.Fatalf("output not terminated by separator: %q", out)
+	}
+
+	var o []map[string]any
+	for _, s := range strings.Split(strings.TrimSuffix(out, sep), sep) {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			t.Fatalf("invalid record %q: %v", s, err)
+		}
+		o = append(o, m)
+	}
+	return o
+}
+
+func TestJSONLoggerSeparator(t *testing.T) {
+	out := jsonOutput(func(x JSONLogger) {
+		x.SetSeparator("\x00")
+		x.Log(Info, "first", Entry{"text", "a\x00b"})
+		x.Log(Info, "second")
+	})
+
+	records := jsonRecords(t, out, "\x00")
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %q", out)
+	}
+	if v := records[0]["text"]; v != "a\x00b" {
+		t.Fatalf("embedded NUL not preserved: %q", v)
+	}
+	if v := records[1]["msg"]; v != "second" {
+		t.Fatalf("unexpected second record: %v", records[1])
+	}
+}
+
+func TestJSONLoggerPrintableSeparator(t *testing.T) {
+	out := jsonOutput(func(x JSONLogger) {
+		x.SetSeparator("|")
+		x.Log(Info, "a|b")
+	})
+
+	if strings.Count(out, "|") != 1 {
+		t.Fatalf("separator not escaped inside the record: %q", out)
+	}
+}
+
+func TestJSONLoggerMarshalError(t *testing.T) {
+	out := jsonOutput(func(x JSONLogger) {
+		x.Log(Info, "msg", Entries{{"ok", 1}, {"bad", make(chan int)}})
+	})
+
+	m := jsonRecords(t, out, "\n")[0]
+	if m["ok"] != 1.0 {
+		t.Fatalf("valid entry lost: %v", m)
+	}
+	block, ok := m[FormatErrorKey].(map[string]any)
+	if !ok || block["key"] != "bad" || block["error"] == "" {
+		t.Fatalf("unexpected %s block: %v", FormatErrorKey, m[FormatErrorKey])
+	}
+}
+
+// jsonBody strips the leading time member of a single record
+func jsonBody(out string) string {
+	return out[strings.Index(out, `"level"`):]
+}
+
+func TestJSONLoggerGroup(t *testing.T) {
+	out := jsonOutput(func(x JSONLogger) {
+		x.SetGroup(true)
+		x.Log(Info, "msg",
+			Entries{{"item", 1}, {"item", 2}},
+			Entries{{"item", 3}, {"other", Entries{{"k", 1}, {"k", 2}}}, {"item", 4}},
+		)
+	})
+
+	const expected = `"level":"INFO","msg":"msg","item":[1,2,3],"other":{"k":1,"k":2},"item":4}` + "\n"
+	if body := jsonBody(out); body != expected {
+		t.Fatalf("expected %s, got %s", expected, body)
+	}
+}
+
+func TestJSONLoggerMaxKeys(t *testing.T) {
+	wide := make(Entries, 100)
+	for i := range wide {
+		wide[i] = Entry{strconv.Itoa(i), i}
+	}
+
+	out := jsonOutput(func(x JSONLogger) {
+		x.SetMaxKeys(3)
+		x.Log(Info, "msg", Entries{{"a", 1}}, Entries{{"wide", wide}, {"b", 2}, {"c", 3}})
+	})
+
+	const expected = `"level":"INFO","msg":"msg","a":1,"wide":{"0":0,"1":1,"2":2,"_truncated":97},"b":2,"_truncated":1}` + "\n"
+	if body := jsonBody(out); body != expected {
+		t.Fatalf("expected %s, got %s", expected, body)
+	}
+	jsonRecords(t, out, "\n")
+}
+
+func TestJSONLoggerMaxKeysNested(t *testing.T) {
+	wideMap := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		wideMap[fmt.Sprintf("k%02d", i)] = i
+	}
+
+	out := jsonOutput(func(x JSONLogger) {
+		x.SetMaxKeys(2)
+		x.Log(Info, "msg", Entries{
+			{"map", wideMap},
+			{"list", logger.List{1, Entries{{"a", 1}, {"b", 2}, {"c", 3}}, map[string]map[string]int{"outer": wideMap}}},
+		})
+	})
+
+	const expected = `"level":"INFO","msg":"msg",` +
+		`"map":{"k00":0,"k01":1,"_truncated":98},` +
+		`"list":[1,{"a":1,"b":2,"_truncated":1},{"outer":{"k00":0,"k01":1,"_truncated":98}}]}` + "\n"
+	if body := jsonBody(out); body != expected {
+		t.Fatalf("expected %s, got %s", expected, body)
+	}
+	jsonRecords(t, out, "\n")
+}
+
+func TestJSONLoggerMapKeys(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+
+	out := jsonOutput(func(x JSONLogger) {
+		x.Log(Info, "msg", Entries{
+			{"ints", map[int]string{2: "b", 1: "a"}},
+			{"structs", map[point]int{{1, 2}: 3}},
+			{"nested", map[string][]map[point]int{"a": {{{1, 2}: 3}}}},
+		})
+	})
+
+	const expected = `"level":"INFO","msg":"msg","ints":[{"key":1,"value":"a"},{"key":2,"value":"b"}],"structs":[{"key":{"X":1,"Y":2},"value":3}],` +
+		`"nested":{"a":[[{"key":{"X":1,"Y":2},"value":3}]]}}` + "\n"
+	if body := jsonBody(out); body != expected {
+		t.Fatalf("expected %s, got %s", expected, body)
+	}
+}
@@ -0,0 +1,50 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// lazyLogger defers the construction of its destination until the first log.
+type lazyLogger struct {
+	f    func() Logger
+	once sync.Once
+
+	forwarder
+	ready atomic.Bool // set after dst has been constructed
+}
+
+// LazyLogger returns a Logger that calls f on its first Log call, and then forwards all logs to the returned Logger.
+// f is called exactly once, even under concurrent use.
+//
+// Useful for libraries and setups that should not construct expensive backends unless something actually gets logged.
+//
+// The returned Logger is also a Preformatter and a Closer. Preformatting only takes place once the destination is ready, otherwise input is returned unchanged, so as not to trigger construction.
+// Closing is a NoOp if the destination was never constructed.
+func LazyLogger(f func() Logger) Logger {
+	return &lazyLogger{f: f}
+}
+
+func (x *lazyLogger) Close() {
+	if !x.ready.Load() {
+		return
+	}
+	x.forwarder.Close()
+}
+
+func (x *lazyLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	x.once.Do(x.init)
+	x.dst.Log(lvl, msg, e...)
+}
+
+func (x *lazyLogger) Preformat(e EntriesGiver) EntriesGiver {
+	if !x.ready.Load() {
+		return e
+	}
+	return x.forwarder.Preformat(e)
+}
+
+func (x *lazyLogger) init() {
+	x.dst = x.f()
+	x.ready.Store(true)
+}
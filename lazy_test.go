@@ -0,0 +1,54 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/blitz-frost/log/logger"
+)
+
+func TestLazyLogger(t *testing.T) {
+	var calls atomic.Int32
+	dst := &recorder{}
+	x := LazyLogger(func() Logger {
+		calls.Add(1)
+		return dst
+	})
+
+	if n := calls.Load(); n != 0 {
+		t.Fatalf("constructed %d times before the first log", n)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			x.Log(Info, "msg")
+		}()
+	}
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected a single construction, got %d", n)
+	}
+	if n := len(dst.records()); n != 16 {
+		t.Fatalf("expected 16 forwarded logs, got %d", n)
+	}
+
+	x.(logger.Closer).Close()
+	if dst.closed != 1 {
+		t.Fatalf("destination not closed")
+	}
+}
+
+func TestLazyLoggerUnused(t *testing.T) {
+	x := LazyLogger(func() Logger {
+		t.Fatal("constructed without logging")
+		return nil
+	})
+
+	x.(Preformatter).Preformat(Entries{{"a", 1}})
+	x.(logger.Closer).Close()
+}
@@ -29,9 +29,14 @@
 package log
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/blitz-frost/log/logger"
 )
@@ -55,12 +60,45 @@ const (
 // Initialized to a LineLogger to stdout, which will not be closed when the Logger is closed.
 var DefaultLogger Logger = LineLoggerMake(os.Stdout, func() {})
 
+// ErrorFields controls where LogError places the attached Entries of errors created with ErrorMake.
+// Defaults to ErrorFieldsNested.
+var ErrorFields = ErrorFieldsNested
+
+// registered level string forms
+var (
+	levelNames map[int]string
+	levelMux   sync.RWMutex
+)
+
+// MaxErrorDepth is the maximum number of error chain links walked by ErrorEntries.
+const MaxErrorDepth = 64
+
+// TimeLayout is the default timestamp layout of new LineLoggers.
+const TimeLayout = "2006-01-02 15:04:05.000"
+
 type Entry = logger.Entry
 
 type Entries = logger.Entries
 
 type EntriesGiver = logger.EntriesGiver
 
+// ErrorFieldsMode defines how LogError places error Entries.
+type ErrorFieldsMode int
+
+const (
+	// The error is logged as is, under the "err" key. Errors created with ErrorMake become a subblock containing the message, attached Entries and wrapped error.
+	ErrorFieldsNested ErrorFieldsMode = iota
+	// The full error message (including the wrapped chain) is logged under "err", while attached Entries are appended to the top level block.
+	// The Entries of all errors down the chain that were created with ErrorMake are included, outermost first.
+	ErrorFieldsFlat
+	// The full error message is logged under "err", while attached Entries are placed in a sibling subblock, under ErrorFieldsKey.
+	// As with ErrorFieldsFlat, the Entries of the whole chain are included.
+	ErrorFieldsSibling
+)
+
+// ErrorFieldsKey is the key of the attached Entries subblock in ErrorFieldsSibling mode.
+const ErrorFieldsKey = "errFields"
+
 // ErrorLogger is a Logger extension that adds error logging convenience.
 type ErrorLogger struct {
 	Logger
@@ -70,6 +108,27 @@ func (x ErrorLogger) Err(lvl int, msg string, err error, e ...EntriesGiver) {
 	LogError(x, lvl, msg, err, e...)
 }
 
+// forwarder provides Close and Preformat for Loggers that wrap a single destination, by forwarding them to it.
+// Meant to be embedded.
+type forwarder struct {
+	dst Logger
+}
+
+// Close closes the destination, if it is a Closer.
+func (x forwarder) Close() {
+	if c, ok := x.dst.(logger.Closer); ok {
+		c.Close()
+	}
+}
+
+// Preformat uses the destination, if it is a Preformatter. Otherwise returns the input unchanged.
+func (x forwarder) Preformat(e EntriesGiver) EntriesGiver {
+	if p, ok := x.dst.(Preformatter); ok {
+		return p.Preformat(e)
+	}
+	return e
+}
+
 // A Preformatter preprocesses EntriesGivers to a type optimized for a particular Logger.
 type Preformatter interface {
 	Preformat(EntriesGiver) EntriesGiver
@@ -79,7 +138,7 @@ type Preformatter interface {
 // A log consists of multiple key-value pairs (a block). Implementations should support recursive block formatting (the value of a key may be a subblock).
 //
 // Logging is an ubiquitous action, and often the only way errors are handled, therefore a Logger should be error resilient itself and provide best effort functionality.
-// Implementations should panic in case of fatal internal errors. For less severe errors, an "OnError" method could be provided.
+// Implementations should panic in case of fatal internal errors. For less severe errors, a "SetOnError" method could be provided.
 //
 // This interface is meant to be concise and generalistic. A fair degree of optimization is achievable through the use of prefered EntriesGiver implementations.
 //
@@ -93,7 +152,13 @@ type Logger interface {
 type Node struct {
 	dst Logger
 
-	src []EntriesGiver
+	src    []EntriesGiver
+	static int // number of leading static src elements (0 or 1)
+
+	eager    bool     // stringify values at call time
+	priority []string // keys to move to the front of logs
+
+	scope *nodeScope // nil if not scoped
 }
 
 // NodeMake creates a new usable Node using dst as the actual Logger implementation.
@@ -114,68 +179,277 @@ func NodeMake(dst Logger, static EntriesGiver, src ...EntriesGiver) Node {
 		}
 		givers = append(givers, static)
 	}
+	n := len(givers)
 
 	givers = append(givers, src...)
 
 	return Node{
-		dst: dst,
-		src: givers,
+		dst:    dst,
+		src:    givers,
+		static: n,
 	}
 }
 
+// EagerStringify returns a copy of the Node that converts all Entry values that are not known to be immutable to strings at call time, before the log is passed on. See Stringify.
+// Static Entries are exempt, since they are immutable by definition.
+//
+// This guarantees safety under asynchronous formatting, even for EntriesGivers that return mutable values, at the cost of always stringifying and losing structure.
+// Prefer ensuring immutable values where possible.
+func (x Node) EagerStringify() Node {
+	x.eager = true
+	return x
+}
+
 func (x Node) Err(lvl int, msg string, err error, e ...EntriesGiver) {
 	LogError(x, lvl, msg, err, e...)
 }
 
 func (x Node) Log(lvl int, msg string, e ...EntriesGiver) {
+	if x.scope != nil && !x.scope.pass(msg) {
+		return
+	}
+
 	givers := make([]EntriesGiver, len(x.src)+len(e))
 	copy(givers, x.src)
 	copy(givers[len(x.src):], e)
 
+	if x.eager && len(givers) > x.static {
+		givers = append(givers[:x.static:x.static], Stringify(givers[x.static:]...))
+	}
+
+	if len(x.priority) > 0 {
+		givers = x.reorder(givers)
+	}
+
 	x.dst.Log(lvl, msg, givers...)
 }
 
+// Prioritize returns a copy of the Node that moves top level Entries with the given keys to the front of each log, in the given order.
+// The order of the remaining Entries is preserved, as is the order between Entries with the same key.
+//
+// Each EntriesGiver is gathered only once. Static Entries that don't contain any prioritized keys are forwarded as is, retaining any preformatting.
+// All others are replaced by their gathered Entries, excluding the moved ones; caller values are never modified.
+func (x Node) Prioritize(keys ...string) Node {
+	x.priority = append([]string(nil), keys...)
+	return x
+}
+
+// Scope returns a copy of the Node with a new suppression scope, which is shared by all further copies.
+// Within the scope, logs are suppressed according to rules, based on the messages that have already been logged.
+// Typically used for request or transaction scoped Nodes, in order to reconcile mutually exclusive events:
+//
+//	n := node.Scope(SuppressRule{"rolled back", []string{"committed"}})
+//	defer n.Log(Warning, "rolled back") // not logged if the commit went through
+//	...
+//	n.Log(Info, "committed")
+//
+// The scope only remembers messages that appear in some rule's After list, so its memory use is bounded by the rules.
+func (x Node) Scope(rules ...SuppressRule) Node {
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		for _, after := range rule.After {
+			seen[after] = false
+		}
+	}
+
+	x.scope = &nodeScope{
+		rules: append([]SuppressRule(nil), rules...),
+		seen:  seen,
+	}
+	return x
+}
+
+// reorder moves prioritized Entries into a new leading block
+func (x Node) reorder(givers []EntriesGiver) []EntriesGiver {
+	moved := make([]Entries, len(x.priority))
+	o := make([]EntriesGiver, 1, len(givers)+1)
+
+	for j, g := range givers {
+		src := g.Entries()
+
+		var rest Entries
+		split := false
+		for i, entry := range src {
+			k := x.priorityOf(entry.Key)
+			if k < 0 {
+				if split {
+					rest = append(rest, entry)
+				}
+				continue
+			}
+			if !split {
+				rest = append(rest, src[:i]...)
+				split = true
+			}
+			moved[k] = append(moved[k], entry)
+		}
+
+		switch {
+		case split:
+			o = append(o, rest)
+		case j < x.static:
+			// immutable, so it can be forwarded with its preformatting
+			o = append(o, g)
+		default:
+			// don't gather dynamic EntriesGivers twice
+			o = append(o, src)
+		}
+	}
+
+	var front Entries
+	for _, s := range moved {
+		front = append(front, s...)
+	}
+	if len(front) == 0 {
+		return o[1:]
+	}
+	o[0] = front
+
+	return o
+}
+
+// priorityOf returns the priority index of a key, or -1 if it is not prioritized
+func (x Node) priorityOf(key string) int {
+	for i, k := range x.priority {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// A SuppressRule suppresses logs with message Msg, if a log with any of the After messages has already been logged in the same Node scope.
+type SuppressRule struct {
+	Msg   string
+	After []string
+}
+
 // A LineLogger writes logs to an io.Writer using the following format:
 //
-//	LEVEL  msg
+//	2006-01-02 15:04:05.000  LEVEL  msg
 //	key0 - value0
 //	key1 - value1
 //	key2
 //	  subkey0 - subvalue0
 //	  subkey1 - subvalue1
 //
+// logger.List values are written as subblocks, keyed by element index.
+//
 // Its purpose is to provide human readable logs to stdout or local files.
 type LineLogger struct {
 	logger.T[[]byte]
+
+	core *lineCore
 }
 
 // LineLoggerMake returns a usable LineLogger.
 // onClose may be nil, in which case it will default to closing the Writer, if it is also a io.Closer.
-func LineLoggerMake(dst io.Writer, onClose func()) LineLogger {
-	return LineLogger{logger.Make[[]byte](lineCore{
-		w:       dst,
-		onClose: onClose,
-	})}
+//
+// The timestamp uses TimeLayout by default.
+//
+// opts are passed on to logger.Make.
+func LineLoggerMake(dst io.Writer, onClose func(), opts ...logger.Option) LineLogger {
+	c := &lineCore{
+		w:          dst,
+		onClose:    onClose,
+		timeLayout: TimeLayout,
+		separator:  []byte("\n"),
+	}
+	return LineLogger{
+		T:    logger.Make[[]byte](c, opts...),
+		core: c,
+	}
 }
 
 func (x LineLogger) Preformat(e EntriesGiver) EntriesGiver {
 	return lineEntriesMake(e)
 }
 
-// errorBlock is an error type that may contain optional entries for logging.
+// SetColor enables or disables ANSI coloring of the level token in the header line: red for Error and above, yellow for Warning, dim for Debug.
+// Disabled by default.
+//
+// Coloring is only enabled if the destination Writer is a terminal (a character device, as reported by its Stat method, like *os.File has), so that redirected output doesn't end up containing escape codes.
+//
+// Should be called before the LineLogger is put to use.
+func (x LineLogger) SetColor(on bool) {
+	x.core.color = on && isTerminal(x.core.w)
+}
+
+// SetOnError sets a function to handle write and close errors, instead of panicking.
+// f will usually be called from the write goroutine; it must not block indefinitely, or log to the same LineLogger.
+//
+// Should be called before the LineLogger is put to use.
+func (x LineLogger) SetOnError(f func(error)) {
+	x.core.onError = f
+}
+
+// SetSeparator changes the record separator, which is appended after each log. Defaults to an empty line ("\n").
+// Useful for ingestion tools that expect NUL or other delimited streams.
+//
+// If sep is not "\n", any occurrence of it inside a record is replaced by its hex escaped form (\xHH for each byte), so that records can be safely split.
+//
+// Should be called before the LineLogger is put to use.
+func (x LineLogger) SetSeparator(sep string) {
+	x.core.separator = []byte(sep)
+}
+
+// SetTimeLayout changes the time.Format layout used for the header timestamp.
+// An empty layout omits the timestamp altogether, which is useful when the output is already timestamped by something else (journald, docker, etc.).
+//
+// Should be called before the LineLogger is put to use.
+func (x LineLogger) SetTimeLayout(layout string) {
+	x.core.timeLayout = layout
+}
+
+// ErrorBlock is an error type that may contain optional entries for logging. Created with ErrorMake.
 // For calling efficiency, is a single Entry slice that starts with {"msg", [string]}.
 // It may wrap another error, which will be appended as a final {"err", [error]} element.
-type errorBlock []Entry
+//
+// Exported so that it may be extracted using errors.As. See also ErrorEntries.
+type ErrorBlock []Entry
 
-func (x errorBlock) Entries() Entries {
+func (x ErrorBlock) Entries() Entries {
 	return Entries(x)
 }
 
-func (x errorBlock) Error() string {
+func (x ErrorBlock) Error() string {
 	return x[0].Value.(string)
 }
 
-func (x errorBlock) Unwrap() error {
+// fields returns the attached Entries, excluding message and wrapped error.
+func (x ErrorBlock) fields() Entries {
+	o := x[1:]
+	if x.Unwrap() != nil {
+		o = o[:len(o)-1]
+	}
+	return Entries(o)
+}
+
+// chainFields returns the attached Entries of x, followed by those of all ErrorBlocks down the wrapped chain (within MaxErrorDepth), outermost first.
+func (x ErrorBlock) chainFields() Entries {
+	o := x.fields()
+	err := x.Unwrap()
+	for depth := 1; err != nil && depth < MaxErrorDepth; depth++ {
+		if inner, ok := err.(ErrorBlock); ok {
+			o = append(o[:len(o):len(o)], inner.fields()...)
+		}
+		err = errors.Unwrap(err)
+	}
+	return o
+}
+
+// message returns the error message, followed by the messages of the wrapped chain.
+func (x ErrorBlock) message() string {
+	if err := x.Unwrap(); err != nil {
+		if inner, ok := err.(ErrorBlock); ok {
+			return x.Error() + ": " + inner.message()
+		}
+		return x.Error() + ": " + err.Error()
+	}
+	return x.Error()
+}
+
+func (x ErrorBlock) Unwrap() error {
 	v := x[len(x)-1].Value
 	if err, ok := v.(error); ok {
 		return err
@@ -229,6 +503,15 @@ func (x *lineBuffer) appendEntry(e Entry) {
 		x.append(sub)
 		x.space = x.space[:len(x.space)-2]
 
+	case logger.List:
+		// subblock with index keys
+		x.endLine()
+		x.space = append(x.space, "  "...)
+		for i, elem := range sub {
+			x.appendEntry(Entry{strconv.Itoa(i), elem})
+		}
+		x.space = x.space[:len(x.space)-2]
+
 	default:
 		// use default value formatting
 		x.data = append(x.data, " - "...)
@@ -252,9 +535,14 @@ func (x *lineBuffer) reset() {
 type lineCore struct {
 	w       io.Writer
 	onClose func()
+	onError logger.ErrorHandler
+
+	timeLayout string
+	separator  []byte
+	color      bool
 }
 
-func (x lineCore) Close() {
+func (x *lineCore) Close() {
 	if x.onClose != nil {
 		x.onClose()
 		return
@@ -262,30 +550,44 @@ func (x lineCore) Close() {
 
 	if c, ok := x.w.(io.Closer); ok {
 		if err := c.Close(); err != nil {
-			panic(err)
+			x.onError.Handle(err)
 		}
 	}
 }
 
-func (x lineCore) Format(data logger.Data) []byte {
+func (x *lineCore) Format(data logger.Data) []byte {
 	buf := newLineBuffer()
 
-	buf.data = append(buf.data, LevelString(data.Level)...)
+	if x.timeLayout != "" {
+		buf.data = data.Time.AppendFormat(buf.data, x.timeLayout)
+		buf.data = append(buf.data, "  "...)
+	}
+	if code := levelColor(data.Level); x.color && code != "" {
+		buf.data = append(buf.data, code...)
+		buf.data = append(buf.data, LevelString(data.Level)...)
+		buf.data = append(buf.data, colorReset...)
+	} else {
+		buf.data = append(buf.data, LevelString(data.Level)...)
+	}
 	buf.data = append(buf.data, "  "...)
 	buf.data = append(buf.data, data.Message...)
 	buf.data = append(buf.data, '\n')
 
 	for _, elem := range data.Entries {
-		buf.append(elem)
+		buf.append(FilterSuppressed(elem))
 	}
-	buf.data = append(buf.data, '\n')
+
+	if len(x.separator) != 1 || x.separator[0] != '\n' {
+		buf.data = escapeSeparator(buf.data, x.separator)
+	}
+	buf.data = append(buf.data, x.separator...)
 
 	return buf.data
 }
 
-func (x lineCore) Write(b []byte) {
+func (x *lineCore) Write(b []byte) {
 	if _, err := x.w.Write(b); err != nil {
-		panic(err)
+		x.onError.Handle(err)
 	}
 }
 
@@ -317,6 +619,36 @@ func (x lineEntries) Entries() Entries {
 	return x.src
 }
 
+// nodeScope tracks logged messages for Node.Scope
+type nodeScope struct {
+	rules []SuppressRule
+
+	mux  sync.Mutex
+	seen map[string]bool // messages that can trigger suppression -> whether they have been logged
+}
+
+// pass checks if a message should be logged, and records it if so
+func (x *nodeScope) pass(msg string) bool {
+	x.mux.Lock()
+	defer x.mux.Unlock()
+
+	for _, rule := range x.rules {
+		if rule.Msg != msg {
+			continue
+		}
+		for _, after := range rule.After {
+			if x.seen[after] {
+				return false
+			}
+		}
+	}
+
+	if _, ok := x.seen[msg]; ok {
+		x.seen[msg] = true
+	}
+	return true
+}
+
 // Close closes the DefaultLogger if it is a Closer.
 func Close() {
 	if c, ok := DefaultLogger.(logger.Closer); ok {
@@ -329,8 +661,35 @@ func Err(lvl int, msg string, err error, e ...EntriesGiver) {
 	LogError(DefaultLogger, lvl, msg, err, e...)
 }
 
-// Predefined level string forms (the constant identifier in all uppercase)
+// ErrorEntries returns a {"err", [block]} Entry that describes the whole Unwrap chain of err, retaining the attached Entries of all ErrorBlocks or other EntriesGivers along the way.
+// Returns nil if err is nil.
+//
+// Each link in the chain is described by a block, with the next link nested under "err":
+//   - ErrorBlock - message, attached Entries, wrapped error
+//   - other EntriesGiver - its Entries, wrapped error
+//   - other errors - {"msg", [Error()]}, wrapped error
+//
+// Links that have no further EntriesGivers down the chain are reduced to their Error() string.
+// Only single error wrapping is followed (Unwrap() error).
+//
+// Walking stops after MaxErrorDepth links, which also protects against cyclic chains. The "err" Entry of the last link is then replaced by a FormatErrorKey block.
+func ErrorEntries(err error) Entries {
+	if err == nil {
+		return nil
+	}
+	return Entries{{"err", errorValue(err, 0)}}
+}
+
+// Predefined level string forms (the constant identifier in all uppercase), or the name set through RegisterLevel.
+// Unknown levels return an empty string.
 func LevelString(lvl int) string {
+	levelMux.RLock()
+	name, ok := levelNames[lvl]
+	levelMux.RUnlock()
+	if ok {
+		return name
+	}
+
 	switch lvl {
 	case Default:
 		return "DEFAULT"
@@ -361,15 +720,31 @@ func Log(lvl int, msg string, e ...EntriesGiver) {
 
 // LogError is a convenience function to handle errors of arbitrary type.
 // Typically used to create "Err" methods.
+//
+// The placement of Entries attached to errors created with ErrorMake is controlled by ErrorFields.
 func LogError(x Logger, lvl int, msg string, err error, e ...EntriesGiver) {
-	e = append(e, Entry{"err", err})
+	block, ok := err.(ErrorBlock)
+	if !ok || ErrorFields == ErrorFieldsNested {
+		e = append(e, Entry{"err", err})
+		x.Log(lvl, msg, e...)
+		return
+	}
+
+	e = append(e, Entry{"err", block.message()})
+	if fields := block.chainFields(); len(fields) > 0 {
+		if ErrorFields == ErrorFieldsSibling {
+			e = append(e, Entry{ErrorFieldsKey, fields})
+		} else {
+			e = append(e, fields)
+		}
+	}
 	x.Log(lvl, msg, e...)
 }
 
 // ErrorMake creates a new error value that implements Entries and may contain additional logging information.
 // If err is non-nil, the new error will wrap it.
 func ErrorMake(msg string, err error, e ...EntriesGiver) error {
-	o := errorBlock{Entry{"msg", msg}}
+	o := ErrorBlock{Entry{"msg", msg}}
 
 	for _, elem := range e {
 		o = append(o, elem.Entries()...)
@@ -382,6 +757,20 @@ func ErrorMake(msg string, err error, e ...EntriesGiver) error {
 	return o
 }
 
+// RegisterLevel sets the string form of a custom level, to be returned by LevelString.
+// Can also be used to override the string form of predefined levels.
+//
+// Concurrent safe, but should typically be called during init.
+func RegisterLevel(lvl int, name string) {
+	levelMux.Lock()
+	defer levelMux.Unlock()
+
+	if levelNames == nil {
+		levelNames = make(map[int]string)
+	}
+	levelNames[lvl] = name
+}
+
 // Preformat uses the DefaultLogger if it is a Preformatter.
 // Otherwise returns the input unchanged.
 func Preformat(e EntriesGiver) EntriesGiver {
@@ -390,3 +779,123 @@ func Preformat(e EntriesGiver) EntriesGiver {
 	}
 	return e
 }
+
+// Stringify gathers Entries from e, converting all values that are not known to be immutable to their default string form (fmt.Sprint), or Error() for errors.
+// Subblocks are converted recursively. Strings, booleans, numbers, time.Time, time.Duration and SecretValue are kept as is.
+//
+// Useful for logging mutable values safely when formatting is asynchronous, at the cost of losing their structure.
+func Stringify(e ...EntriesGiver) Entries {
+	var o Entries
+	for _, g := range e {
+		for _, entry := range g.Entries() {
+			o = append(o, Entry{entry.Key, stringifyValue(entry.Value)})
+		}
+	}
+	return o
+}
+
+// escapeSeparator replaces all occurrences of sep in b with their hex escaped form.
+func escapeSeparator(b, sep []byte) []byte {
+	if len(sep) == 0 || !bytes.Contains(b, sep) {
+		return b
+	}
+
+	esc := make([]byte, 0, 4*len(sep))
+	for _, c := range sep {
+		esc = append(esc, '\\', 'x', hexDigits[c>>4], hexDigits[c&0xf])
+	}
+
+	return bytes.ReplaceAll(b, sep, esc)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// errorValue returns the structured form of an error chain link, or its Error() string if there is nothing structured down the chain.
+func errorValue(err error, depth int) any {
+	var o Entries
+	switch v := err.(type) {
+	case ErrorBlock:
+		o = append(o, v[0])
+		o = append(o, v.fields()...)
+	case EntriesGiver:
+		o = append(o, v.Entries()...)
+	default:
+		if !hasGiver(err, depth) {
+			return err.Error()
+		}
+		o = append(o, Entry{"msg", err.Error()})
+	}
+
+	if next := errors.Unwrap(err); next != nil {
+		if depth+1 < MaxErrorDepth {
+			o = append(o, Entry{"err", errorValue(next, depth+1)})
+		} else {
+			o = append(o, Entry{FormatErrorKey, Entries{{"key", "err"}, {"error", "error chain exceeds MaxErrorDepth"}}})
+		}
+	}
+	return o
+}
+
+// hasGiver checks if there is an EntriesGiver down the error chain, within MaxErrorDepth.
+// Like errors.As, but safe against cycles.
+func hasGiver(err error, depth int) bool {
+	for ; err != nil && depth < MaxErrorDepth; depth++ {
+		if _, ok := err.(EntriesGiver); ok {
+			return true
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
+
+// ANSI color codes used by LineLogger
+const (
+	colorDim    = "\x1b[2m"
+	colorRed    = "\x1b[31m"
+	colorReset  = "\x1b[0m"
+	colorYellow = "\x1b[33m"
+)
+
+// isTerminal checks if w is a character device.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Stat() (os.FileInfo, error) })
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// levelColor returns the ANSI color code of a level, or an empty string if it should not be colored.
+func levelColor(lvl int) string {
+	switch {
+	case lvl >= Error:
+		return colorRed
+	case lvl == Warning:
+		return colorYellow
+	case lvl == Debug:
+		return colorDim
+	}
+	return ""
+}
+
+// stringifyValue returns v if it is immutable, or its string form otherwise
+func stringifyValue(v any) any {
+	switch val := v.(type) {
+	case nil, string, bool,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, complex64, complex128,
+		time.Time, time.Duration, SecretValue:
+		return v
+	case EntriesGiver:
+		return Stringify(val)
+	case error:
+		return val.Error()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,347 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// record is a single log captured by a recorder
+type record struct {
+	lvl int
+	msg string
+	e   Entries
+}
+
+// recorder is a Logger that gathers logs synchronously and keeps them in memory
+type recorder struct {
+	mux    sync.Mutex
+	logs   []record
+	closed int
+}
+
+func (x *recorder) Close() {
+	x.mux.Lock()
+	x.closed++
+	x.mux.Unlock()
+}
+
+func (x *recorder) Log(lvl int, msg string, e ...EntriesGiver) {
+	var o Entries
+	for _, g := range e {
+		o = append(o, g.Entries()...)
+	}
+
+	x.mux.Lock()
+	x.logs = append(x.logs, record{lvl, msg, o})
+	x.mux.Unlock()
+}
+
+func (x *recorder) records() []record {
+	x.mux.Lock()
+	defer x.mux.Unlock()
+	return append([]record(nil), x.logs...)
+}
+
+// lineOutput returns the output of a LineLogger without timestamps, after f is done using it
+func lineOutput(f func(LineLogger)) string {
+	var buf bytes.Buffer
+	x := LineLoggerMake(&buf, func() {})
+	x.SetTimeLayout("")
+	f(x)
+	x.Close()
+	return buf.String()
+}
+
+// lookup returns the value of the first Entry with key k
+func lookup(e Entries, k string) (any, bool) {
+	for _, entry := range e {
+		if entry.Key == k {
+			return entry.Value, true
+		}
+	}
+	return nil, false
+}
+
+func TestLineLogger(t *testing.T) {
+	out := lineOutput(func(x LineLogger) {
+		x.Log(Warning, "msg", Entries{{"a", 1}, {"sub", Entries{{"b", "x"}}}})
+	})
+
+	const expected = "WARNING  msg\na - 1\nsub\n  b - x\n\n"
+	if out != expected {
+		t.Fatalf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestLineLoggerSeparator(t *testing.T) {
+	out := lineOutput(func(x LineLogger) {
+		x.SetSeparator("\x00")
+		x.Log(Info, "first", Entry{"text", "a\x00b"})
+		x.Log(Info, "second")
+	})
+
+	records := strings.Split(out, "\x00")
+	if len(records) != 3 || records[2] != "" {
+		t.Fatalf("expected 2 NUL terminated records, got %q", out)
+	}
+	if expected := "INFO  first\ntext - a\\x00b\n"; records[0] != expected {
+		t.Fatalf("expected %q, got %q", expected, records[0])
+	}
+	if expected := "INFO  second\n"; records[1] != expected {
+		t.Fatalf("expected %q, got %q", expected, records[1])
+	}
+}
+
+func TestErrorFields(t *testing.T) {
+	defer func(mode ErrorFieldsMode) { ErrorFields = mode }(ErrorFields)
+
+	err := ErrorMake("outer", ErrorMake("inner", nil, Entry{"k", 1}), Entry{"o", 2})
+
+	logWith := func(mode ErrorFieldsMode) Entries {
+		ErrorFields = mode
+		var x recorder
+		LogError(&x, Error, "fail", err, Entry{"a", 0})
+		return x.records()[0].e
+	}
+
+	t.Run("nested", func(t *testing.T) {
+		e := logWith(ErrorFieldsNested)
+		if len(e) != 2 || e[0].Key != "a" || e[1].Key != "err" {
+			t.Fatalf("unexpected entries: %v", e)
+		}
+		if _, ok := e[1].Value.(ErrorBlock); !ok {
+			t.Fatalf("error not logged as is: %v", e[1].Value)
+		}
+	})
+
+	t.Run("flat", func(t *testing.T) {
+		e := logWith(ErrorFieldsFlat)
+		expected := Entries{{"a", 0}, {"err", "outer: inner"}, {"o", 2}, {"k", 1}}
+		if !entriesEqual(e, expected) {
+			t.Fatalf("expected %v, got %v", expected, e)
+		}
+	})
+
+	t.Run("sibling", func(t *testing.T) {
+		e := logWith(ErrorFieldsSibling)
+		if len(e) != 3 || e[1] != (Entry{"err", "outer: inner"}) || e[2].Key != ErrorFieldsKey {
+			t.Fatalf("unexpected entries: %v", e)
+		}
+		expected := Entries{{"o", 2}, {"k", 1}}
+		if fields := e[2].Value.(Entries); !entriesEqual(fields, expected) {
+			t.Fatalf("expected %v, got %v", expected, fields)
+		}
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		ErrorFields = ErrorFieldsSibling
+		var x recorder
+		plain := errors.New("plain")
+		LogError(&x, Error, "fail", plain)
+		if e := x.records()[0].e; len(e) != 1 || e[0] != (Entry{"err", plain}) {
+			t.Fatalf("plain error not logged as is: %v", e)
+		}
+	})
+}
+
+// entriesEqual compares flat Entries with comparable values
+func entriesEqual(a, b Entries) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// cyclicError unwraps to itself
+type cyclicError struct {
+	e Entries
+}
+
+func (x cyclicError) Entries() Entries { return x.e }
+func (x cyclicError) Error() string    { return "cycle" }
+func (x cyclicError) Unwrap() error    { return x }
+
+func TestErrorEntries(t *testing.T) {
+	bottom := ErrorMake("bottom", nil, Entry{"b", 1})
+	mid := fmt.Errorf("mid: %w", bottom)
+	top := ErrorMake("top", mid, Entry{"t", 0})
+
+	expected := Entries{{"err", Entries{
+		{"msg", "top"},
+		{"t", 0},
+		{"err", Entries{
+			{"msg", "mid: bottom"},
+			{"err", Entries{
+				{"msg", "bottom"},
+				{"b", 1},
+			}},
+		}},
+	}}}
+	if e := ErrorEntries(top); !reflect.DeepEqual(e, expected) {
+		t.Fatalf("expected %v, got %v", expected, e)
+	}
+
+	var block ErrorBlock
+	if !errors.As(mid, &block) || block.Error() != "bottom" {
+		t.Fatalf("ErrorBlock not extracted: %v", block)
+	}
+
+	plain := fmt.Errorf("wrapped: %w", errors.New("plain"))
+	if e := ErrorEntries(plain); !reflect.DeepEqual(e, Entries{{"err", "wrapped: plain"}}) {
+		t.Fatalf("chain without EntriesGivers not logged as a string: %v", e)
+	}
+
+	// must terminate, marking the truncation
+	e := ErrorEntries(cyclicError{Entries{{"k", 1}}})
+	depth := 0
+	for {
+		link := e[len(e)-1]
+		if link.Key == FormatErrorKey {
+			break
+		}
+		if link.Key != "err" {
+			t.Fatalf("unexpected link entry: %v", link)
+		}
+		e = link.Value.(Entries)
+		depth++
+	}
+	if depth != MaxErrorDepth {
+		t.Fatalf("expected %d links, got %d", MaxErrorDepth, depth)
+	}
+}
+
+// countingGiver counts its Entries calls
+type countingGiver struct {
+	e     Entries
+	calls *int
+}
+
+func (x countingGiver) Entries() Entries {
+	*x.calls++
+	return x.e
+}
+
+func TestNodePrioritize(t *testing.T) {
+	var x recorder
+	var calls int
+	dynamic := countingGiver{Entries{{"d", 0}}, &calls}
+	n := NodeMake(&x, Entries{{"s", 0}, {"id", 7}}, dynamic).Prioritize("id", "user")
+
+	e := Entries{{"a", 1}, {"user", "bob"}, {"b", 2}}
+	n.Log(Info, "msg", e)
+
+	expected := Entries{{"id", 7}, {"user", "bob"}, {"s", 0}, {"d", 0}, {"a", 1}, {"b", 2}}
+	if got := x.records()[0].e; !entriesEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	if !entriesEqual(e, Entries{{"a", 1}, {"user", "bob"}, {"b", 2}}) {
+		t.Fatalf("caller Entries modified: %v", e)
+	}
+	if calls != 1 {
+		t.Fatalf("dynamic EntriesGiver gathered %d times", calls)
+	}
+}
+
+func TestNodeScope(t *testing.T) {
+	var x recorder
+	base := NodeMake(&x, nil)
+	rule := SuppressRule{"rolled back", []string{"committed"}}
+
+	committed := base.Scope(rule)
+	committed.Log(Info, "committed")
+	committed.Log(Warning, "rolled back") // suppressed
+
+	failed := base.Scope(rule)
+	failed.Prioritize().Log(Warning, "rolled back") // copies share the scope
+	failed.Log(Info, "rolled back")
+
+	base.Log(Warning, "rolled back") // unscoped
+
+	var msgs []string
+	for _, r := range x.records() {
+		msgs = append(msgs, r.msg)
+	}
+	expected := []string{"committed", "rolled back", "rolled back", "rolled back"}
+	if strings.Join(msgs, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected %v, got %v", expected, msgs)
+	}
+}
+
+func TestNodeScopeMemory(t *testing.T) {
+	var x recorder
+	n := NodeMake(&x, nil).Scope(SuppressRule{"rolled back", []string{"committed"}})
+
+	for i := 0; i < 100; i++ {
+		n.Log(Info, strconv.Itoa(i))
+	}
+	n.Log(Info, "committed")
+
+	if len(n.scope.seen) != 1 || !n.scope.seen["committed"] {
+		t.Fatalf("unexpected messages remembered: %v", n.scope.seen)
+	}
+}
+
+func TestLineLoggerMapKeys(t *testing.T) {
+	out := lineOutput(func(x LineLogger) {
+		x.Log(Info, "msg", Entry{"ints", map[int]string{2: "b", 1: "a"}})
+	})
+
+	const expected = "INFO  msg\nints\n  0\n    key - 1\n    value - a\n  1\n    key - 2\n    value - b\n\n"
+	if out != expected {
+		t.Fatalf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestStringify(t *testing.T) {
+	type mutable struct {
+		n []int
+	}
+	now := time.Now()
+	v := &mutable{[]int{1}}
+
+	e := Stringify(
+		Entries{{"s", "x"}, {"i", 1}, {"t", now}, {"d", time.Second}, {"secret", Secret(1)}},
+		Entries{{"ptr", v}, {"err", errors.New("fail")}, {"sub", Entries{{"slice", []int{1, 2}}}}},
+	)
+
+	expected := Entries{
+		{"s", "x"},
+		{"i", 1},
+		{"t", now},
+		{"d", time.Second},
+		{"secret", SecretValue{}},
+		{"ptr", fmt.Sprint(v)},
+		{"err", "fail"},
+		{"sub", Entries{{"slice", "[1 2]"}}},
+	}
+	if !reflect.DeepEqual(e, expected) {
+		t.Fatalf("expected %v, got %v", expected, e)
+	}
+}
+
+func TestNodeEagerStringify(t *testing.T) {
+	var x recorder
+	static := Entries{{"static", []int{0}}}
+	n := NodeMake(&x, static).EagerStringify()
+
+	s := []int{1}
+	n.Log(Info, "msg", Entry{"slice", s})
+	s[0] = 2
+
+	expected := Entries{{"static", []int{0}}, {"slice", "[1]"}}
+	if e := x.records()[0].e; !reflect.DeepEqual(e, expected) {
+		t.Fatalf("expected %v, got %v", expected, e)
+	}
+}
@@ -1,6 +1,11 @@
 // Package logger provides utilities for Logger implementations.
 package logger
 
+import (
+	"sync/atomic"
+	"time"
+)
+
 type Closer interface {
 	Close()
 }
@@ -13,8 +18,21 @@ type Core[Raw any] interface {
 	Closer           // ensure all commited logs are fulfilled + cleanup
 }
 
+// ErrorHandler is used by Cores to handle internal errors.
+// The nil value panics, which is the default behaviour for fatal errors.
+type ErrorHandler func(error)
+
+// Handle passes err to the handler, or panics if it is nil.
+func (x ErrorHandler) Handle(err error) {
+	if x == nil {
+		panic(err)
+	}
+	x(err)
+}
+
 // Data can be used to transfer Log calls between goroutines.
 type Data struct {
+	Time    time.Time // moment of the Log call
 	Level   int
 	Message string
 	Entries []Entries
@@ -41,6 +59,28 @@ func (x Entry) Entries() Entries {
 	return Entries{x}
 }
 
+// An Option configures a T on creation.
+type Option func(*options)
+
+// WithOverflow sets the behaviour of Log calls when the internal queue is full.
+func WithOverflow(o Overflow) Option {
+	return func(x *options) {
+		x.overflow = o
+	}
+}
+
+type options struct {
+	overflow Overflow
+}
+
+// Overflow defines what happens to logs that don't fit in a T's queue, which fills up when the backend can't keep up.
+type Overflow int
+
+const (
+	Block      Overflow = iota // Log blocks until there is room; the default
+	DropNewest                 // Log discards the incoming data and returns immediately; see T.Dropped
+)
+
 // T is the default Logger implementation.
 //
 // It is concurrent safe, and should be capable of handling pretty high volumes.
@@ -52,17 +92,29 @@ type T[Raw any] struct {
 	writeChan chan chan Raw // queue raw formatted data to dedicated write goroutine
 
 	done chan struct{} // closed when the write loop exits
+
+	overflow Overflow
+	dropped  *atomic.Uint64 // number of logs discarded due to overflow
 }
 
 // Make creates a Logger using the provided Core.
 //
 // The Format method of the provided Core must be concurrent safe.
-func Make[Raw any](c Core[Raw]) T[Raw] {
+//
+// By default, Log calls block while the queue is full. See WithOverflow for alternatives.
+func Make[Raw any](c Core[Raw], opts ...Option) T[Raw] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	x := T[Raw]{
 		c:         c,
 		dataChan:  make(chan Data, 8),
 		writeChan: make(chan chan Raw, 8),
 		done:      make(chan struct{}),
+		overflow:  o.overflow,
+		dropped:   new(atomic.Uint64),
 	}
 
 	go x.run()
@@ -80,18 +132,43 @@ func (x T[Raw]) Close() {
 	x.c.Close()
 }
 
+// Dropped returns the total number of logs that have been discarded due to the overflow policy.
+func (x T[Raw]) Dropped() uint64 {
+	return x.dropped.Load()
+}
+
+// Log gathers Entries synchronously, passing them through Snapshot, then queues the log for formatting and writing.
 func (x T[Raw]) Log(lvl int, msg string, e ...EntriesGiver) {
+	// timestamp before anything else, formatting may happen much later
+	x.LogAt(time.Now(), lvl, msg, e...)
+}
+
+// LogAt is like Log, but uses t as the log timestamp, instead of the moment of the call.
+// Meant for replaying logs that were recorded earlier.
+func (x T[Raw]) LogAt(t time.Time, lvl int, msg string, e ...EntriesGiver) {
 	// gather entries synchronously
 	s := make([]Entries, len(e))
 	for i := range e {
-		s[i] = e[i].Entries()
+		s[i] = Snapshot(e[i].Entries())
 	}
 
-	x.dataChan <- Data{
+	data := Data{
+		Time:    t,
 		Level:   lvl,
 		Message: msg,
 		Entries: s,
 	}
+
+	if x.overflow == DropNewest {
+		select {
+		case x.dataChan <- data:
+		default:
+			x.dropped.Add(1)
+		}
+		return
+	}
+
+	x.dataChan <- data
 }
 
 func (x T[Raw]) format(data Data, ch chan Raw) {
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingCore holds up Write calls until released, keeping the written messages
+type blockingCore struct {
+	release chan struct{}
+
+	mux     sync.Mutex
+	written []string
+	closed  bool
+}
+
+func (x *blockingCore) Format(data Data) string {
+	return data.Message
+}
+
+func (x *blockingCore) Write(s string) {
+	<-x.release
+
+	x.mux.Lock()
+	x.written = append(x.written, s)
+	x.mux.Unlock()
+}
+
+func (x *blockingCore) Close() {
+	x.mux.Lock()
+	x.closed = true
+	x.mux.Unlock()
+}
+
+func TestDropNewest(t *testing.T) {
+	const n = 100
+
+	c := &blockingCore{release: make(chan struct{})}
+	x := Make[string](c, WithOverflow(DropNewest))
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < n; i++ {
+			x.Log(0, strconv.Itoa(i))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Log blocked on a full queue")
+	}
+
+	dropped := x.Dropped()
+	if dropped == 0 || dropped >= n {
+		t.Fatalf("unexpected drop count: %d", dropped)
+	}
+
+	close(c.release)
+	x.Close()
+
+	if !c.closed {
+		t.Fatal("core not closed")
+	}
+	if uint64(len(c.written)) != n-dropped {
+		t.Fatalf("expected %d accepted logs to be written, got %d", n-dropped, len(c.written))
+	}
+	last := -1
+	for _, s := range c.written {
+		i, _ := strconv.Atoi(s)
+		if i <= last {
+			t.Fatalf("logs written out of order: %v", c.written)
+		}
+		last = i
+	}
+	if c.written[0] != "0" {
+		t.Fatalf("oldest log dropped instead of the newest: %v", c.written)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	c := &blockingCore{release: make(chan struct{})}
+	x := Make[string](c)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			x.Log(0, strconv.Itoa(i))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Log didn't block on a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(c.release)
+	<-done
+	x.Close()
+
+	if len(c.written) != 100 || x.Dropped() != 0 {
+		t.Fatalf("expected all logs to be written, got %d", len(c.written))
+	}
+}
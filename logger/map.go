@@ -0,0 +1,219 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// List is an ordered collection of values. Logger implementations should render it as an array, with EntriesGiver elements as blocks.
+type List []any
+
+// Snapshot replaces map values that have non-string keys, which most formats can't represent consistently, with a List of {"key": k, "value": v} blocks, sorted by key.
+// Subblocks are processed recursively. Subblocks that are not plain Entries are gathered here, once, and replaced by their Entries, so formatters don't have to gather them again.
+//
+// Lists, slices, arrays and maps with string keys are searched as well. If anything is replaced inside them, they are copied into a new List, or Entries sorted by key, respectively.
+// Struct fields and pointer targets are not searched, and are passed on as is. Neither is anything nested deeper than 64 levels, which guards against cyclic values.
+//
+// Meant to be called synchronously in Log calls, so that the maps are copied before they can be modified.
+// e is never modified; if anything is replaced, a new Entries is returned.
+func Snapshot(e Entries) Entries {
+	o, _ := snapshot(e, 0)
+	return o
+}
+
+// maximum nesting level searched by Snapshot; protects against cyclic values
+const snapshotDepth = 64
+
+// snapshot also reports whether anything was replaced
+func snapshot(e Entries, depth int) (Entries, bool) {
+	var o Entries // nil until something changes
+
+	for i, entry := range e {
+		v, changed := snapshotValue(entry.Value, depth)
+		if !changed {
+			continue
+		}
+
+		if o == nil {
+			o = make(Entries, len(e))
+			copy(o, e)
+		}
+		o[i].Value = v
+	}
+
+	if o == nil {
+		return e, false
+	}
+	return o, true
+}
+
+// snapshotValue returns the replacement of v, if any
+func snapshotValue(v any, depth int) (any, bool) {
+	if depth++; depth > snapshotDepth {
+		return v, false
+	}
+
+	switch val := v.(type) {
+	case nil, string, bool,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, time.Time, time.Duration, error:
+		// fast path for common types
+		return v, false
+	case Entries:
+		if o, changed := snapshot(val, depth); changed {
+			return o, true
+		}
+		return v, false
+	case EntriesGiver:
+		// gather once
+		o, _ := snapshot(val.Entries(), depth)
+		return o, true
+	case List:
+		var o List // nil until something changes
+		for i, elem := range val {
+			sub, changed := snapshotValue(elem, depth)
+			if o == nil && changed {
+				o = make(List, len(val))
+				copy(o, val)
+			}
+			if o != nil {
+				o[i] = sub
+			}
+		}
+		if o == nil {
+			return v, false
+		}
+		return o, true
+	}
+
+	// only types that can hold maps with non-string keys need the reflect path
+	t := reflect.TypeOf(v)
+	if !needsSnapshot(t) {
+		return v, false
+	}
+
+	r := reflect.ValueOf(v)
+	switch t.Kind() {
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return snapshotMap(r, depth), true
+		}
+		return snapshotStringMap(r, depth)
+	case reflect.Slice, reflect.Array:
+		return snapshotSlice(r, depth)
+	}
+	return v, false
+}
+
+// snapshotMap converts a map with non-string keys to a sorted List of key/value blocks
+func snapshotMap(r reflect.Value, depth int) List {
+	keys := r.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return lessKey(keys[i], keys[j])
+	})
+
+	o := make(List, len(keys))
+	for i, k := range keys {
+		elem, _ := snapshotValue(r.MapIndex(k).Interface(), depth)
+		o[i] = Entries{
+			{"key", k.Interface()},
+			{"value", elem},
+		}
+	}
+	return o
+}
+
+// snapshotStringMap converts a map with string keys to Entries sorted by key, if any of its values are replaced
+func snapshotStringMap(r reflect.Value, depth int) (any, bool) {
+	o := make(Entries, 0, r.Len())
+	replaced := false
+	for it := r.MapRange(); it.Next(); {
+		elem, changed := snapshotValue(it.Value().Interface(), depth)
+		o = append(o, Entry{it.Key().String(), elem})
+		replaced = replaced || changed
+	}
+
+	if !replaced {
+		return r.Interface(), false
+	}
+	sort.Slice(o, func(i, j int) bool {
+		return o[i].Key < o[j].Key
+	})
+	return o, true
+}
+
+// snapshotSlice returns a List copy of a slice or array, if any of its elements are replaced
+func snapshotSlice(r reflect.Value, depth int) (any, bool) {
+	var o List // nil until something changes
+
+	n := r.Len()
+	for i := 0; i < n; i++ {
+		elem, changed := snapshotValue(r.Index(i).Interface(), depth)
+		if o == nil && changed {
+			o = make(List, n)
+			for j := 0; j < i; j++ {
+				o[j] = r.Index(j).Interface()
+			}
+		}
+		if o != nil {
+			o[i] = elem
+		}
+	}
+
+	if o == nil {
+		return r.Interface(), false
+	}
+	return o, true
+}
+
+// type -> whether values may hold maps with non-string keys
+var snapshotTypes sync.Map
+
+// needsSnapshot checks if values of type t may hold maps with non-string keys, through map values, slice and array elements or interfaces.
+func needsSnapshot(t reflect.Type) bool {
+	if o, ok := snapshotTypes.Load(t); ok {
+		return o.(bool)
+	}
+
+	o := typeNeedsSnapshot(t, make(map[reflect.Type]bool))
+	snapshotTypes.Store(t, o)
+	return o
+}
+
+// typeNeedsSnapshot is the uncached form of needsSnapshot; visiting guards against recursive types.
+func typeNeedsSnapshot(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return true
+		}
+		return typeNeedsSnapshot(t.Elem(), visiting)
+	case reflect.Slice, reflect.Array:
+		return typeNeedsSnapshot(t.Elem(), visiting)
+	}
+	return false
+}
+
+// lessKey orders map keys: numerically if possible, otherwise by their default string form
+func lessKey(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	}
+	return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+}
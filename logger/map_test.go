@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type point struct {
+	X, Y int
+}
+
+// countingGiver counts its Entries calls
+type countingGiver struct {
+	e     Entries
+	calls *int
+}
+
+func (x countingGiver) Entries() Entries {
+	*x.calls++
+	return x.e
+}
+
+func TestSnapshotIntKeys(t *testing.T) {
+	m := map[int]string{10: "b", -1: "a", 2: "c"}
+	e := Entries{{"m", m}}
+	o := Snapshot(e)
+
+	expected := Entries{{"m", List{
+		Entries{{"key", -1}, {"value", "a"}},
+		Entries{{"key", 2}, {"value", "c"}},
+		Entries{{"key", 10}, {"value", "b"}},
+	}}}
+	if !reflect.DeepEqual(o, expected) {
+		t.Fatalf("expected %v, got %v", expected, o)
+	}
+	if _, ok := e[0].Value.(map[int]string); !ok {
+		t.Fatal("input modified")
+	}
+
+	// the snapshot doesn't follow later changes
+	m[0] = "new"
+	if len(o[0].Value.(List)) != 3 {
+		t.Fatal("snapshot changed along with the map")
+	}
+}
+
+func TestSnapshotStructKeys(t *testing.T) {
+	m := map[point]map[int]bool{{1, 2}: {1: true}}
+	o := Snapshot(Entries{{"sub", Entries{{"m", m}}}})
+
+	expected := Entries{{"sub", Entries{{"m", List{
+		Entries{{"key", point{1, 2}}, {"value", List{
+			Entries{{"key", 1}, {"value", true}},
+		}}},
+	}}}}}
+	if !reflect.DeepEqual(o, expected) {
+		t.Fatalf("expected %v, got %v", expected, o)
+	}
+}
+
+func TestSnapshotUnchanged(t *testing.T) {
+	e := Entries{
+		{"s", "x"},
+		{"t", time.Time{}},
+		{"d", time.Second},
+		{"i32", int32(1)},
+		{"struct", point{}},
+		{"strmap", map[string]int{"a": 1}},
+		{"anymap", map[string]any{"a": List{1}}},
+		{"bytes", []byte("x")},
+		{"sub", Entries{{"a", 1}}},
+	}
+	if o := Snapshot(e); &o[0] != &e[0] {
+		t.Fatal("Entries without replacements copied")
+	}
+}
+
+func TestSnapshotGathersOnce(t *testing.T) {
+	var calls int
+	o := Snapshot(Entries{{"sub", countingGiver{Entries{{"a", 1}}, &calls}}})
+
+	sub, ok := o[0].Value.(Entries)
+	if !ok || !reflect.DeepEqual(sub, Entries{{"a", 1}}) {
+		t.Fatalf("subblock not replaced by its Entries: %v", o[0].Value)
+	}
+	if calls != 1 {
+		t.Fatalf("subblock gathered %d times", calls)
+	}
+}
+
+func TestSnapshotNested(t *testing.T) {
+	inner := map[int]string{1: "a"}
+	innerList := List{Entries{{"key", 1}, {"value", "a"}}}
+
+	strMap := map[string]map[int]string{"m": inner}
+	slice := []map[int]string{inner}
+	list := List{"x", inner}
+	e := Entries{{"strmap", strMap}, {"slice", slice}, {"list", list}, {"array", [1]any{inner}}}
+	o := Snapshot(e)
+
+	expected := Entries{
+		{"strmap", Entries{{"m", innerList}}},
+		{"slice", List{innerList}},
+		{"list", List{"x", innerList}},
+		{"array", List{innerList}},
+	}
+	if !reflect.DeepEqual(o, expected) {
+		t.Fatalf("expected %v, got %v", expected, o)
+	}
+
+	// caller values are never modified
+	if _, ok := list[1].(map[int]string); !ok {
+		t.Fatal("input List modified")
+	}
+	if _, ok := e[0].Value.(map[string]map[int]string); !ok {
+		t.Fatal("input Entries modified")
+	}
+}
+
+func TestSnapshotCyclic(t *testing.T) {
+	m := map[string]any{"n": map[int]int{1: 1}}
+	m["self"] = m
+
+	// must terminate
+	o := Snapshot(Entries{{"m", m}})
+	v, ok := o[0].Value.(Entries)
+	if !ok || v[0].Key != "n" {
+		t.Fatalf("map not converted: %v", o[0].Value)
+	}
+	if _, ok := v[0].Value.(List); !ok {
+		t.Fatalf("nested map not replaced: %v", o[0].Value)
+	}
+}
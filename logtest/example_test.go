@@ -0,0 +1,30 @@
+package logtest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/blitz-frost/log"
+	"github.com/blitz-frost/log/logtest"
+)
+
+// printTB prints logs to stdout, standing in for the *testing.T of an actual test.
+type printTB struct {
+	testing.TB
+}
+
+func (printTB) Helper() {}
+
+func (printTB) Log(args ...any) {
+	fmt.Println(args...)
+}
+
+func ExampleTBLogger() {
+	// in an actual test: x := logtest.TBLogger(t)
+	x := logtest.TBLogger(printTB{})
+
+	n := log.NodeMake(x, log.Entries{{"component", "db"}})
+	n.Log(log.Warning, "slow query", log.Entry{"ms", 250}, log.Entry{"query", log.Entries{{"table", "users"}}})
+
+	// Output: WARNING  slow query  component=db ms=250 query={table=users}
+}
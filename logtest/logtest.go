@@ -0,0 +1,93 @@
+// Package logtest provides a Logger for use in tests.
+package logtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/blitz-frost/log"
+	"github.com/blitz-frost/log/logger"
+)
+
+// tbLogger writes logs through a testing.TB.
+type tbLogger struct {
+	tb testing.TB
+}
+
+// TBLogger returns a Logger that writes logs through tb.Log, so that they are attributed to the running test and only shown if it fails (or in verbose mode).
+// Logs are formatted synchronously, as single lines:
+//
+//	LEVEL  msg  key0=value0 key1={subkey0=subvalue0 subkey1=subvalue1}
+//
+// The returned Logger is also a Preformatter (identity) and a Closer (NoOp), so it can stand in for other Loggers.
+// Like tb.Log itself, it must not be used after the test completes.
+//
+// Typical usage, for code that accepts a Logger:
+//
+//	func TestSomething(t *testing.T) {
+//		x := somepkg.New(logtest.TBLogger(t))
+//		...
+//	}
+func TBLogger(tb testing.TB) log.Logger {
+	return tbLogger{tb}
+}
+
+func (x tbLogger) Close() {}
+
+func (x tbLogger) Log(lvl int, msg string, e ...log.EntriesGiver) {
+	x.tb.Helper()
+
+	var b strings.Builder
+	b.WriteString(log.LevelString(lvl))
+	b.WriteString("  ")
+	b.WriteString(msg)
+	if len(e) > 0 {
+		b.WriteByte(' ')
+	}
+	for _, g := range e {
+		for _, entry := range log.FilterSuppressed(g.Entries()) {
+			b.WriteByte(' ')
+			appendEntry(&b, entry)
+		}
+	}
+
+	x.tb.Log(b.String())
+}
+
+func (x tbLogger) Preformat(e log.EntriesGiver) log.EntriesGiver {
+	return e
+}
+
+func appendEntry(b *strings.Builder, e log.Entry) {
+	b.WriteString(e.Key)
+	b.WriteByte('=')
+	appendValue(b, e.Value)
+}
+
+func appendValue(b *strings.Builder, v any) {
+	switch val := v.(type) {
+	case log.EntriesGiver:
+		b.WriteByte('{')
+		for i, entry := range val.Entries() {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			appendEntry(b, entry)
+		}
+		b.WriteByte('}')
+	case logger.List:
+		b.WriteByte('[')
+		for i, elem := range val {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			appendValue(b, elem)
+		}
+		b.WriteByte(']')
+	case error:
+		b.WriteString(val.Error())
+	default:
+		fmt.Fprint(b, v)
+	}
+}
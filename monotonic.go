@@ -0,0 +1,51 @@
+package log
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// MonotonicKey is the key of the Entry added by MonotonicLogger.
+const MonotonicKey = "mono"
+
+// A MonotonicLogger adds a MonotonicKey Entry to each log, holding a nanosecond timestamp that is strictly increasing across all MonotonicLoggers in the process.
+// Restores the call order of logs that share the same wall clock timestamp, as well as of logs that end up in different destinations.
+//
+// Values are assigned at call time, in nanoseconds since the Unix epoch, as measured by the runtime's monotonic clock from the moment the package was initialized.
+// They are therefore unaffected by wall clock adjustments, and will gradually drift from the log timestamp for long running processes.
+// When calls happen within the same nanosecond, the value is bumped by 1 to stay strictly increasing.
+//
+// Unlike a sequence number, values are not contiguous, so they cannot be used to detect missing logs, but they do carry time information and are comparable across Loggers.
+type MonotonicLogger struct {
+	forwarder
+}
+
+// MonotonicMake returns a MonotonicLogger that forwards to dst.
+func MonotonicMake(dst Logger) MonotonicLogger {
+	return MonotonicLogger{forwarder{dst}}
+}
+
+func (x MonotonicLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	e = append(e[:len(e):len(e)], Entry{MonotonicKey, monotonicNext()})
+	x.dst.Log(lvl, msg, e...)
+}
+
+var (
+	monotonicStart = time.Now()
+	monotonicLast  atomic.Int64
+)
+
+// monotonicNext returns the next MonotonicLogger value
+func monotonicNext() int64 {
+	now := monotonicStart.UnixNano() + int64(time.Since(monotonicStart))
+	for {
+		last := monotonicLast.Load()
+		next := now
+		if next <= last {
+			next = last + 1
+		}
+		if monotonicLast.CompareAndSwap(last, next) {
+			return next
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package log
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMonotonicLogger(t *testing.T) {
+	var dst recorder
+	x := MonotonicMake(&dst)
+
+	const n = 10000
+	for i := 0; i < n; i++ {
+		x.Log(Info, "msg")
+	}
+
+	var prev int64
+	for i, r := range dst.records() {
+		v, ok := lookup(r.e, MonotonicKey)
+		if !ok {
+			t.Fatalf("log %d: missing %s", i, MonotonicKey)
+		}
+		if cur := v.(int64); cur <= prev {
+			t.Fatalf("log %d: %d not greater than %d", i, cur, prev)
+		} else {
+			prev = cur
+		}
+	}
+}
+
+func TestMonotonicLoggerConcurrent(t *testing.T) {
+	const routines, n = 8, 1000
+
+	var wg sync.WaitGroup
+	values := make([][]int64, routines)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				values[i] = append(values[i], monotonicNext())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool, routines*n)
+	for _, s := range values {
+		for j, v := range s {
+			if seen[v] {
+				t.Fatalf("duplicate value %d", v)
+			}
+			seen[v] = true
+			if j > 0 && v <= s[j-1] {
+				t.Fatalf("%d not greater than %d", v, s[j-1])
+			}
+		}
+	}
+}
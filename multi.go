@@ -0,0 +1,178 @@
+package log
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/blitz-frost/log/logger"
+)
+
+// A MultiLogger forwards each log to multiple Loggers.
+//
+// Preformatting is performed independently for each destination that is a Preformatter, so each one receives its own optimized form.
+type MultiLogger struct {
+	dst []Logger
+
+	closers []logger.Closer // closed instead of dst, if set
+}
+
+// MultiLoggerMake returns a MultiLogger that forwards to dst, in order.
+func MultiLoggerMake(dst ...Logger) MultiLogger {
+	return MultiLogger{
+		dst: append([]Logger(nil), dst...),
+	}
+}
+
+// DualLogger returns a MultiLogger that writes human readable logs (LineLogger) to human and machine readable ones (JSONLogger) to machine.
+// Useful when migrating between formats, or to have a live console view alongside a log file.
+//
+// The Writers are not closed along with the Logger; they remain the caller's responsibility.
+func DualLogger(human io.Writer, machine io.Writer) MultiLogger {
+	return MultiLoggerMake(
+		LineLoggerMake(human, func() {}),
+		JSONLoggerMake(machine, func() {}),
+	)
+}
+
+// A Tier pairs a destination Logger with the minimum level of logs it receives. Used by TieredLogger.
+type Tier struct {
+	Logger   Logger
+	MinLevel int // Default means all logs
+}
+
+// TieredLogger returns a MultiLogger that forwards each log to the tiers whose minimum level it meets, in order.
+// Covers the common setup of writing everything to a file, but only warnings and above to the console:
+//
+//	TieredLogger(Tier{file, Debug}, Tier{console, Warning})
+//
+// Each destination is wrapped in a FilterLogger (unless its MinLevel is Default), so preformatting still reaches every destination individually.
+// Closing the MultiLogger closes each distinct destination exactly once, even if it appears in multiple tiers. Destinations are considered the same if they are comparable and equal.
+// Note that such a destination still receives logs that meet multiple tiers multiple times.
+func TieredLogger(tiers ...Tier) MultiLogger {
+	o := MultiLogger{
+		dst:     make([]Logger, len(tiers)),
+		closers: []logger.Closer{},
+	}
+	for i, t := range tiers {
+		if t.MinLevel == Default {
+			o.dst[i] = t.Logger
+		} else {
+			o.dst[i] = FilterMake(t.Logger, t.MinLevel)
+		}
+
+		c, ok := t.Logger.(logger.Closer)
+		if !ok {
+			continue
+		}
+		dup := false
+		for _, prev := range tiers[:i] {
+			if sameLogger(prev.Logger, t.Logger) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			o.closers = append(o.closers, c)
+		}
+	}
+	return o
+}
+
+// Close closes all destinations that are Closers.
+func (x MultiLogger) Close() {
+	if x.closers != nil {
+		for _, c := range x.closers {
+			c.Close()
+		}
+		return
+	}
+
+	for _, dst := range x.dst {
+		if c, ok := dst.(logger.Closer); ok {
+			c.Close()
+		}
+	}
+}
+
+func (x MultiLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	for i := range x.dst {
+		x.forward(i, lvl, msg, e)
+	}
+}
+
+func (x MultiLogger) Preformat(e EntriesGiver) EntriesGiver {
+	if len(x.dst) == 0 {
+		return e
+	}
+
+	if pre, ok := e.(multiEntries); ok {
+		if x.owns(pre) {
+			return pre
+		}
+		e = pre.src
+	}
+
+	o := multiEntries{
+		owner: x.dst,
+		src:   e,
+		dst:   make([]EntriesGiver, len(x.dst)),
+	}
+	for i, dst := range x.dst {
+		if p, ok := dst.(Preformatter); ok {
+			o.dst[i] = p.Preformat(e)
+		} else {
+			o.dst[i] = e
+		}
+	}
+
+	return o
+}
+
+// forward logs to the i-th destination only
+func (x MultiLogger) forward(i int, lvl int, msg string, e []EntriesGiver) {
+	givers := e
+	copied := false
+
+	// substitute own preformatted entries with the destination specific form
+	// the input slice is copied, rather than modified
+	for j := range e {
+		pre, ok := e[j].(multiEntries)
+		if !ok || !x.owns(pre) {
+			continue
+		}
+		if !copied {
+			givers = make([]EntriesGiver, len(e))
+			copy(givers, e)
+			copied = true
+		}
+		givers[j] = pre.dst[i]
+	}
+
+	x.dst[i].Log(lvl, msg, givers...)
+}
+
+// owns checks if e was preformatted by x
+func (x MultiLogger) owns(e multiEntries) bool {
+	return len(e.owner) == len(x.dst) && &e.owner[0] == &x.dst[0]
+}
+
+// sameLogger checks if a and b are the same value, without panicking on values that can't be compared
+func sameLogger(a, b Logger) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	return va.Type() == vb.Type() && va.Comparable() && va.Equal(vb)
+}
+
+// multiEntries holds the preformatted forms of the same Entries, for each destination of a MultiLogger.
+type multiEntries struct {
+	owner []Logger // identifies the MultiLogger that created the value
+
+	src EntriesGiver
+	dst []EntriesGiver // same order as owner
+}
+
+func (x multiEntries) Entries() Entries {
+	return x.src.Entries()
+}
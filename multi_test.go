@@ -0,0 +1,113 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDualLogger(t *testing.T) {
+	var human, machine bytes.Buffer
+	x := DualLogger(&human, &machine)
+	n := NodeMake(x, Entries{{"static", 1}})
+	n.Log(Warning, "msg", Entry{"a", "b"})
+	x.Close()
+
+	if out := human.String(); !strings.Contains(out, "WARNING  msg\nstatic - 1\na - b\n") {
+		t.Fatalf("unexpected human output: %q", out)
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(machine.Bytes(), &m); err != nil {
+		t.Fatalf("invalid machine output %q: %v", machine.String(), err)
+	}
+	if m["level"] != "WARNING" || m["msg"] != "msg" || m["static"] != 1.0 || m["a"] != "b" {
+		t.Fatalf("unexpected machine output: %v", m)
+	}
+}
+
+func TestMultiLoggerPreformat(t *testing.T) {
+	line := LineLoggerMake(&bytes.Buffer{}, func() {})
+	js := JSONLoggerMake(&bytes.Buffer{}, func() {})
+	rec := &recorder{}
+	x := MultiLoggerMake(line, js, rec)
+	defer x.Close()
+
+	pre, ok := x.Preformat(Entries{{"a", 1}}).(multiEntries)
+	if !ok {
+		t.Fatal("not preformatted")
+	}
+	if _, ok := pre.dst[0].(lineEntries); !ok {
+		t.Fatalf("line destination got %T", pre.dst[0])
+	}
+	if _, ok := pre.dst[1].(jsonEntries); !ok {
+		t.Fatalf("JSON destination got %T", pre.dst[1])
+	}
+
+	x.Log(Info, "msg", pre)
+	if e := rec.records()[0].e; !entriesEqual(e, Entries{{"a", 1}}) {
+		t.Fatalf("unexpected entries: %v", e)
+	}
+}
+
+func TestTieredLogger(t *testing.T) {
+	all, warn, plain := &recorder{}, &recorder{}, &recorder{}
+	x := TieredLogger(
+		Tier{all, Debug},
+		Tier{warn, Warning},
+		Tier{plain, Default},
+	)
+
+	n := NodeMake(x, Entries{{"static", 1}})
+	n.Log(Debug, "debug")
+	n.Log(Info, "info")
+	n.Log(Error, "error")
+	x.Close()
+
+	msgs := func(r *recorder) string {
+		var o []string
+		for _, rec := range r.records() {
+			o = append(o, rec.msg)
+			if v, _ := lookup(rec.e, "static"); v != 1 {
+				t.Fatalf("static entries lost: %v", rec.e)
+			}
+		}
+		return strings.Join(o, ",")
+	}
+	if m := msgs(all); m != "debug,info,error" {
+		t.Fatalf("unexpected Debug tier logs: %s", m)
+	}
+	if m := msgs(warn); m != "error" {
+		t.Fatalf("unexpected Warning tier logs: %s", m)
+	}
+	if m := msgs(plain); m != "debug,info,error" {
+		t.Fatalf("unexpected Default tier logs: %s", m)
+	}
+	if all.closed != 1 || warn.closed != 1 || plain.closed != 1 {
+		t.Fatal("destinations not closed once")
+	}
+}
+
+func TestTieredLoggerSharedDestination(t *testing.T) {
+	var buf bytes.Buffer
+	line := LineLoggerMake(&buf, func() {})
+	rec := &recorder{}
+	x := TieredLogger(
+		Tier{line, Debug},
+		Tier{line, Warning},
+		Tier{rec, Info},
+		Tier{rec, Error},
+		Tier{MultiLoggerMake(), Info}, // not comparable
+	)
+
+	x.Log(Info, "msg")
+	x.Close() // would panic if line was closed twice
+
+	if rec.closed != 1 {
+		t.Fatalf("shared destination closed %d times", rec.closed)
+	}
+	if n := strings.Count(buf.String(), "INFO  msg"); n != 1 {
+		t.Fatalf("expected 1 log, got %d", n)
+	}
+}
@@ -0,0 +1,107 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// An OverheadLogger measures the time that Log calls spend on the calling goroutine, in order to find logging hotspots.
+// Since formatting and writing are typically asynchronous, this is mostly the cost of Entry gathering and queuing, which grows with expensive EntriesGivers or a blocked backend.
+//
+// Every Nth call is timed, and once enough samples are collected, their aggregate is passed to a report function and a new window begins.
+// The last window is reported on Close, even if incomplete.
+//
+// Concurrent safe.
+type OverheadLogger struct {
+	forwarder
+	setup OverheadSetup
+
+	count *atomic.Uint64 // total calls, for sampling
+
+	mux   *sync.Mutex
+	stats *OverheadStats // current window
+}
+
+// OverheadSetup configures an OverheadLogger. Report is mandatory.
+type OverheadSetup struct {
+	Every  int                 // time every Nth call; values below 2 time every call
+	Window int                 // number of samples per report; defaults to 1000
+	Report func(OverheadStats) // called synchronously by the Log call that completes a window; should return quickly
+}
+
+// OverheadStats aggregates the timed Log calls of a reporting window.
+type OverheadStats struct {
+	Samples int
+	Total   time.Duration
+	Max     time.Duration
+}
+
+// Mean returns the average duration of a Log call.
+func (x OverheadStats) Mean() time.Duration {
+	if x.Samples == 0 {
+		return 0
+	}
+	return x.Total / time.Duration(x.Samples)
+}
+
+// OverheadMake returns an OverheadLogger that forwards to dst.
+func OverheadMake(dst Logger, setup OverheadSetup) OverheadLogger {
+	if setup.Every < 1 {
+		setup.Every = 1
+	}
+	if setup.Window < 1 {
+		setup.Window = 1000
+	}
+
+	return OverheadLogger{
+		forwarder: forwarder{dst},
+		setup:     setup,
+		count:     new(atomic.Uint64),
+		mux:       new(sync.Mutex),
+		stats:     new(OverheadStats),
+	}
+}
+
+// Close reports the last partial window, if it has any samples, then closes the destination, if it is a Closer.
+func (x OverheadLogger) Close() {
+	x.mux.Lock()
+	stats := *x.stats
+	*x.stats = OverheadStats{}
+	x.mux.Unlock()
+
+	if stats.Samples > 0 {
+		x.setup.Report(stats)
+	}
+
+	x.forwarder.Close()
+}
+
+func (x OverheadLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	if (x.count.Add(1)-1)%uint64(x.setup.Every) != 0 {
+		x.dst.Log(lvl, msg, e...)
+		return
+	}
+
+	start := time.Now()
+	x.dst.Log(lvl, msg, e...)
+	d := time.Since(start)
+
+	x.mux.Lock()
+	x.stats.Samples++
+	x.stats.Total += d
+	if d > x.stats.Max {
+		x.stats.Max = d
+	}
+
+	if x.stats.Samples < x.setup.Window {
+		x.mux.Unlock()
+		return
+	}
+
+	stats := *x.stats
+	*x.stats = OverheadStats{}
+	x.mux.Unlock()
+
+	x.setup.Report(stats)
+}
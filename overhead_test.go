@@ -0,0 +1,50 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+// slowLogger sleeps on each Log call
+type slowLogger struct {
+	d time.Duration
+}
+
+func (x slowLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	time.Sleep(x.d)
+}
+
+func TestOverheadLogger(t *testing.T) {
+	var reports []OverheadStats
+	x := OverheadMake(slowLogger{time.Millisecond}, OverheadSetup{
+		Every:  2,
+		Window: 2,
+		Report: func(s OverheadStats) {
+			reports = append(reports, s)
+		},
+	})
+
+	for i := 0; i < 6; i++ {
+		x.Log(Info, "msg")
+	}
+
+	// calls 0, 2 and 4 are timed; the first two fill a window
+	if len(reports) != 1 {
+		t.Fatalf("expected 1 report, got %d", len(reports))
+	}
+	s := reports[0]
+	if s.Samples != 2 || s.Max < time.Millisecond || s.Total < 2*time.Millisecond || s.Mean() < time.Millisecond {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+
+	// the partial window is reported on Close
+	x.Close()
+	if len(reports) != 2 || reports[1].Samples != 1 {
+		t.Fatalf("partial window not reported: %+v", reports)
+	}
+
+	x.Close()
+	if len(reports) != 2 {
+		t.Fatal("empty window reported")
+	}
+}
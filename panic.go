@@ -0,0 +1,60 @@
+package log
+
+import (
+	"runtime"
+	"strconv"
+)
+
+// PanicEntries packages a recovered panic value and the stack of the panicking goroutine into a loggable block:
+//
+//	value - recovered value
+//	stack
+//	  0
+//	    func - main.f
+//	    file - /src/main.go
+//	    line - 12
+//	  1
+//	    ...
+//
+// Must be called from the deferred function that recovered, so that the panicking frames are still on the stack:
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			Log(Critical, "panic", PanicEntries(r))
+//		}
+//	}()
+//
+// Frames are numbered starting from the one that panicked. The stack is captured on the spot, so the result is safe for asynchronous logging.
+func PanicEntries(recovered any) EntriesGiver {
+	pc := make([]uintptr, 64)
+	n := runtime.Callers(2, pc) // skip runtime.Callers and PanicEntries
+	frames := runtime.CallersFrames(pc[:n])
+
+	// drop frames up to and including the panic machinery, if present
+	var all []runtime.Frame
+	start := 0
+	for {
+		frame, more := frames.Next()
+		all = append(all, frame)
+		if frame.Function == "runtime.gopanic" {
+			start = len(all)
+		}
+		if !more {
+			break
+		}
+	}
+
+	stack := make(Entries, 0, len(all)-start)
+	for i, frame := range all[start:] {
+		stack = append(stack, Entry{strconv.Itoa(i), Entries{
+			{"func", frame.Function},
+			{"file", frame.File},
+			{"line", frame.Line},
+		}})
+	}
+
+	return Entries{
+		{"value", recovered},
+		{"stack", stack},
+	}
+}
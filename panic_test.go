@@ -0,0 +1,47 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+// panicking panics with v
+func panicking(v any) {
+	panic(v)
+}
+
+func TestPanicEntries(t *testing.T) {
+	var e Entries
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				e = PanicEntries(r).Entries()
+			}
+		}()
+		panicking("boom")
+	}()
+
+	if v, _ := lookup(e, "value"); v != "boom" {
+		t.Fatalf("unexpected value: %v", v)
+	}
+
+	v, _ := lookup(e, "stack")
+	stack, ok := v.(Entries)
+	if !ok || len(stack) == 0 {
+		t.Fatalf("missing stack: %v", e)
+	}
+
+	first := stack[0].Value.(Entries)
+	if stack[0].Key != "0" {
+		t.Fatalf("frames not numbered from 0: %v", stack[0])
+	}
+	if fn, _ := lookup(first, "func"); !strings.HasSuffix(fn.(string), ".panicking") {
+		t.Fatalf("first frame is not the panicking function: %v", first)
+	}
+	if file, _ := lookup(first, "file"); !strings.HasSuffix(file.(string), "panic_test.go") {
+		t.Fatalf("unexpected file: %v", first)
+	}
+	if line, _ := lookup(first, "line"); line.(int) <= 0 {
+		t.Fatalf("unexpected line: %v", first)
+	}
+}
@@ -10,30 +10,46 @@ var ProcedureName = "Log"
 
 type Logger struct {
 	logger.T[logger.Data]
+
+	core *core
 }
 
 // BindTo binds a logging procedure to an rpc.Client, and returns a Logger that wraps this procedure.
 //
-// The underlying rpc system must be capable of handling interface types in general, as well as recognizing at least logger.Entries when used as interface values in particular.
+// The underlying rpc system must be capable of handling interface types in general, as well as recognizing at least logger.Entries and logger.List when used as interface values in particular.
 //
-// onClose may be nil.
+// onClose may be nil. opts are passed on to logger.Make.
 //
 // The used name can be controlled through the ProcedureName global variable.
-func BindTo(cli rpc.Client, onClose func()) (Logger, error) {
+func BindTo(cli rpc.Client, onClose func(), opts ...logger.Option) (Logger, error) {
 	var f func(logger.Data) error
 	if err := cli.Bind(ProcedureName, &f); err != nil {
 		return Logger{}, err
 	}
 
-	return Logger{logger.Make[logger.Data](core{
+	c := &core{
 		f:       f,
 		onClose: onClose,
-	})}, nil
+	}
+	return Logger{
+		T:    logger.Make[logger.Data](c, opts...),
+		core: c,
+	}, nil
+}
+
+// SetOnError sets a function to handle procedure call errors, instead of panicking.
+// f is called from the write goroutine; it must not block indefinitely, or log to the same Logger.
+//
+// Should be called before the Logger is put to use.
+func (x Logger) SetOnError(f func(error)) {
+	x.core.onError = f
 }
 
 // RegisterWith registers a logging procedure to an rpc.Library. The procesure will use dst as the actual server-side Logger implementation.
 //
-// The underlying rpc system must be capable of handling interface types in general, as well as recognizing at least logger.Entries when used as interface values in particular.
+// The underlying rpc system must be capable of handling interface types in general, as well as recognizing at least logger.Entries and logger.List when used as interface values in particular.
+//
+// If dst is a log.TimedLogger, logs keep the timestamp of the client-side call.
 //
 // The used name can be controlled through the ProcedureName global variable.
 func RegisterWith(lib rpc.Library, dst log.Logger) error {
@@ -43,7 +59,11 @@ func RegisterWith(lib rpc.Library, dst log.Logger) error {
 			e = append(e, s...)
 		}
 		// being able to pass whatever you want individually, but not as part of a slice of elements that satisfy the required interface, is such a nice language feature innit?
-		dst.Log(data.Level, data.Message, e)
+		if t, ok := dst.(log.TimedLogger); ok && !data.Time.IsZero() {
+			t.LogAt(data.Time, data.Level, data.Message, e)
+		} else {
+			dst.Log(data.Level, data.Message, e)
+		}
 		return nil
 	}
 	return lib.Register(ProcedureName, f)
@@ -52,9 +72,10 @@ func RegisterWith(lib rpc.Library, dst log.Logger) error {
 type core struct {
 	f       func(logger.Data) error
 	onClose func()
+	onError logger.ErrorHandler
 }
 
-func (x core) Close() {
+func (x *core) Close() {
 	if x.onClose != nil {
 		x.onClose()
 	}
@@ -62,39 +83,88 @@ func (x core) Close() {
 
 // Format replaces error Entry.Values with their error string, otherwise it might not really mean much to the receiver if concrete type information is lost.
 // Also ensures there are only Entries instead of EntriesGivers.
-func (x core) Format(data logger.Data) logger.Data {
-	for _, e := range data.Entries {
-		format(e)
+// Suppressed keys are dropped before sending.
+//
+// Entries and Lists that need replacements are copied, since they may still be referenced by the caller.
+func (x *core) Format(data logger.Data) logger.Data {
+	s := make([]log.Entries, len(data.Entries))
+	for i, e := range data.Entries {
+		s[i], _ = format(log.FilterSuppressed(e))
 	}
+	data.Entries = s
 	return data
 }
 
-func (x core) Write(data logger.Data) {
+func (x *core) Write(data logger.Data) {
 	if err := x.f(data); err != nil {
-		panic(err)
+		x.onError.Handle(err)
+	}
+}
+
+// format returns e with its values replaced, and whether anything was replaced
+// a new slice is only allocated if needed
+func format(e log.Entries) (log.Entries, bool) {
+	var o log.Entries // nil until something changes
+	for i, entry := range e {
+		v, changed := formatValue(entry.Value)
+		if !changed {
+			continue
+		}
+		if o == nil {
+			o = make(log.Entries, len(e))
+			copy(o, e)
+		}
+		o[i].Value = v
+	}
+
+	if o == nil {
+		return e, false
 	}
+	return o, true
 }
 
-func format(e log.Entries) {
-	for i := range e {
-		v := e[i].Value
-		switch val := v.(type) {
-		case log.Entries:
-			// recursive format
+// formatValue returns the replacement of v, and whether it differs from v
+func formatValue(v any) (any, bool) {
+	switch val := v.(type) {
+	case log.Entries:
+		// recursive format
 
-			format(val)
-		case log.EntriesGiver:
-			// replace interface with slice + recursive format
+		return format(val)
+	case log.EntriesGiver:
+		// replace interface with slice + recursive format
 
-			entries := val.Entries()
-			format(entries)
-			e[i].Value = entries
+		entries, _ := format(val.Entries())
+		return entries, true
 
-		case error:
-			// replace interface with string
-			// note that log.errorBlock will satisfy the EntriesGiver branch
+	case logger.List:
+		// recursive format
 
-			e[i].Value = val.Error()
+		var o logger.List // nil until something changes
+		for i, elem := range val {
+			sub, changed := formatValue(elem)
+			if o == nil && changed {
+				o = make(logger.List, len(val))
+				copy(o, val)
+			}
+			if o != nil {
+				o[i] = sub
+			}
 		}
+		if o == nil {
+			return v, false
+		}
+		return o, true
+
+	case log.SecretValue:
+		// the receiver might not know the type
+
+		return log.Redacted, true
+
+	case error:
+		// replace interface with string
+		// note that log.ErrorBlock will satisfy the EntriesGiver branch
+
+		return val.Error(), true
 	}
+	return v, false
 }
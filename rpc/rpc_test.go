@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/blitz-frost/log"
+	"github.com/blitz-frost/log/logger"
+	"github.com/blitz-frost/rpc"
+)
+
+// timedRecorder is a TimedLogger that keeps the timestamp of the last log
+type timedRecorder struct {
+	t     time.Time
+	timed bool
+	e     log.Entries
+}
+
+func (x *timedRecorder) Log(lvl int, msg string, e ...log.EntriesGiver) {
+	x.LogAt(time.Time{}, lvl, msg, e...)
+	x.timed = false
+}
+
+func (x *timedRecorder) LogAt(t time.Time, lvl int, msg string, e ...log.EntriesGiver) {
+	x.t, x.timed = t, true
+	x.e = nil
+	for _, g := range e {
+		x.e = append(x.e, g.Entries()...)
+	}
+}
+
+// call invokes the procedure registered by RegisterWith directly
+func call(t *testing.T, dst log.Logger, data logger.Data) {
+	t.Helper()
+
+	lib := rpc.LibraryMake()
+	if err := RegisterWith(lib, dst); err != nil {
+		t.Fatal(err)
+	}
+	p, err := lib.Get(ProcedureName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Call([]reflect.Value{reflect.ValueOf(data)}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegisterWithTime(t *testing.T) {
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var x timedRecorder
+	call(t, &x, logger.Data{
+		Time:    at,
+		Level:   log.Info,
+		Message: "msg",
+		Entries: []log.Entries{{{"a", 1}}},
+	})
+	if !x.timed || !x.t.Equal(at) {
+		t.Fatalf("call time not kept: %v", x.t)
+	}
+	if len(x.e) != 1 || x.e[0] != (log.Entry{"a", 1}) {
+		t.Fatalf("unexpected entries: %v", x.e)
+	}
+
+	// clients that don't send a timestamp
+	call(t, &x, logger.Data{Level: log.Info, Message: "msg"})
+	if x.timed {
+		t.Fatal("zero time forwarded")
+	}
+}
+
+func TestFormatCopies(t *testing.T) {
+	list := logger.List{errors.New("a"), 1}
+	sub := log.Entries{{"err", errors.New("b")}}
+	top := log.Entries{{"list", list}, {"sub", sub}, {"secret", log.Secret("x")}}
+
+	c := &core{}
+	data := c.Format(logger.Data{Entries: []log.Entries{top}})
+
+	expected := log.Entries{{"list", logger.List{"a", 1}}, {"sub", log.Entries{{"err", "b"}}}, {"secret", log.Redacted}}
+	if !reflect.DeepEqual(data.Entries[0], expected) {
+		t.Fatalf("expected %v, got %v", expected, data.Entries[0])
+	}
+
+	// caller values are left alone
+	if _, ok := list[0].(error); !ok {
+		t.Fatal("List modified")
+	}
+	if _, ok := sub[0].Value.(error); !ok {
+		t.Fatal("subblock modified")
+	}
+	if _, ok := top[2].Value.(log.SecretValue); !ok {
+		t.Fatal("top level Entries modified")
+	}
+}
@@ -0,0 +1,139 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// SampledKey is the key of the Entry through which a SampleLogger reports discarded logs.
+const SampledKey = "sampled_dropped"
+
+// A SampleLogger discards a portion of logs, in order to keep hot paths from flooding the destination.
+//
+// Sampling is done independently for each level, before any Entries are gathered, so discarded logs are cheap.
+// The number of logs discarded since the last one that passed is appended to the next passed log of the same level, as {SampledKey, [uint64]}.
+//
+// Concurrent safe.
+type SampleLogger struct {
+	forwarder
+	setup SampleSetup
+
+	mux   *sync.Mutex
+	state map[int]*sampleState
+}
+
+// SampleSetup configures a SampleLogger. The two sampling modes may be combined, in which case a log must pass both.
+type SampleSetup struct {
+	Every int // pass only every Nth log; values below 2 disable this mode
+
+	Rate  float64 // pass at most this many logs per second, using a token bucket; 0 disables this mode
+	Burst int     // token bucket size; defaults to 1
+
+	Levels []int // levels to sample; if empty, all levels are sampled
+
+	Now func() time.Time // clock used for the token bucket; defaults to time.Now
+}
+
+// SampleMake returns a SampleLogger that forwards to dst.
+func SampleMake(dst Logger, setup SampleSetup) SampleLogger {
+	if setup.Burst < 1 {
+		setup.Burst = 1
+	}
+	if setup.Now == nil {
+		setup.Now = time.Now
+	}
+
+	return SampleLogger{
+		forwarder: forwarder{dst},
+		setup:     setup,
+		mux:       new(sync.Mutex),
+		state:     make(map[int]*sampleState),
+	}
+}
+
+func (x SampleLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	if !x.sampled(lvl) {
+		x.dst.Log(lvl, msg, e...)
+		return
+	}
+
+	pass, dropped := x.take(lvl)
+	if !pass {
+		return
+	}
+
+	if dropped > 0 {
+		// don't modify the input slice
+		e = append(e[:len(e):len(e)], Entry{SampledKey, dropped})
+	}
+	x.dst.Log(lvl, msg, e...)
+}
+
+// sampled checks if lvl is subject to sampling
+func (x SampleLogger) sampled(lvl int) bool {
+	if len(x.setup.Levels) == 0 {
+		return true
+	}
+	for _, v := range x.setup.Levels {
+		if v == lvl {
+			return true
+		}
+	}
+	return false
+}
+
+// take decides if a log of level lvl passes. If it does, also returns the number of logs dropped since the previous pass, and resets it.
+func (x SampleLogger) take(lvl int) (bool, uint64) {
+	x.mux.Lock()
+	defer x.mux.Unlock()
+
+	s, ok := x.state[lvl]
+	if !ok {
+		s = &sampleState{
+			tokens: float64(x.setup.Burst),
+			last:   x.setup.Now(),
+		}
+		x.state[lvl] = s
+	}
+
+	pass := true
+
+	if x.setup.Every > 1 {
+		pass = s.count%uint64(x.setup.Every) == 0
+		s.count++
+	}
+
+	if pass && x.setup.Rate > 0 {
+		now := x.setup.Now()
+		s.tokens += now.Sub(s.last).Seconds() * x.setup.Rate
+		if burst := float64(x.setup.Burst); s.tokens > burst {
+			s.tokens = burst
+		}
+		s.last = now
+
+		if s.tokens >= 1 {
+			s.tokens--
+		} else {
+			pass = false
+		}
+	}
+
+	if !pass {
+		s.dropped++
+		return false, 0
+	}
+
+	dropped := s.dropped
+	s.dropped = 0
+	return true, dropped
+}
+
+// sampleState tracks sampling of a single level.
+type sampleState struct {
+	count   uint64 // total logs, for Every sampling
+	dropped uint64 // since the last passed log
+
+	// token bucket
+	tokens float64
+	last   time.Time
+}
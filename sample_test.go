@@ -0,0 +1,156 @@
+package log
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sampleOutcome lists the messages that passed a SampleLogger, along with their SampledKey values (0 if missing)
+func sampleOutcome(x *recorder) ([]string, []uint64) {
+	var msgs []string
+	var dropped []uint64
+	for _, r := range x.records() {
+		msgs = append(msgs, r.msg)
+		v, _ := lookup(r.e, SampledKey)
+		n, _ := v.(uint64)
+		dropped = append(dropped, n)
+	}
+	return msgs, dropped
+}
+
+func equalSlices[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSampleEvery(t *testing.T) {
+	var dst recorder
+	x := SampleMake(&dst, SampleSetup{Every: 3})
+	for i := 0; i < 9; i++ {
+		x.Log(Info, strconv.Itoa(i))
+	}
+
+	msgs, dropped := sampleOutcome(&dst)
+	if !equalSlices(msgs, []string{"0", "3", "6"}) || !equalSlices(dropped, []uint64{0, 2, 2}) {
+		t.Fatalf("unexpected outcome: %v %v", msgs, dropped)
+	}
+}
+
+func TestSampleRate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var dst recorder
+	x := SampleMake(&dst, SampleSetup{
+		Rate:  2,
+		Burst: 2,
+		Now:   func() time.Time { return now },
+	})
+	logN := func(n int, tag string) {
+		for i := 0; i < n; i++ {
+			x.Log(Info, tag)
+		}
+	}
+
+	logN(5, "burst") // bucket starts full
+	now = now.Add(500 * time.Millisecond)
+	logN(2, "refill") // refills a single token
+	now = now.Add(10 * time.Second)
+	logN(3, "capped") // refill capped at the burst size
+
+	msgs, dropped := sampleOutcome(&dst)
+	expectedMsgs := []string{"burst", "burst", "refill", "capped", "capped"}
+	expectedDropped := []uint64{0, 0, 3, 1, 0}
+	if !equalSlices(msgs, expectedMsgs) || !equalSlices(dropped, expectedDropped) {
+		t.Fatalf("unexpected outcome: %v %v", msgs, dropped)
+	}
+}
+
+func TestSampleCombined(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var dst recorder
+	x := SampleMake(&dst, SampleSetup{
+		Every: 2,
+		Rate:  1,
+		Now:   func() time.Time { return now },
+	})
+
+	for i := 0; i < 4; i++ {
+		// 0 passes; 1 and 3 fail Every; 2 passes Every, but finds the bucket empty
+		x.Log(Info, strconv.Itoa(i))
+	}
+	now = now.Add(time.Second)
+	x.Log(Info, "4")
+
+	msgs, dropped := sampleOutcome(&dst)
+	if !equalSlices(msgs, []string{"0", "4"}) || !equalSlices(dropped, []uint64{0, 3}) {
+		t.Fatalf("unexpected outcome: %v %v", msgs, dropped)
+	}
+}
+
+func TestSampleLevels(t *testing.T) {
+	var dst recorder
+	x := SampleMake(&dst, SampleSetup{
+		Every:  2,
+		Levels: []int{Debug, Warning},
+	})
+
+	for i := 0; i < 4; i++ {
+		x.Log(Info, "info")
+		x.Log(Debug, "debug")
+		x.Log(Warning, "warning")
+	}
+
+	count := make(map[string]int)
+	for _, r := range dst.records() {
+		count[r.msg]++
+	}
+	if count["info"] != 4 {
+		t.Fatalf("unsampled level was sampled: %v", count)
+	}
+	// levels are sampled independently
+	if count["debug"] != 2 || count["warning"] != 2 {
+		t.Fatalf("unexpected sampling: %v", count)
+	}
+}
+
+func TestSampleConcurrent(t *testing.T) {
+	const (
+		workers = 10
+		n       = 100
+	)
+
+	var dst recorder
+	x := SampleMake(&dst, SampleSetup{Every: 10})
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				x.Log(Info, "msg")
+			}
+		}()
+	}
+	wg.Wait()
+
+	msgs, dropped := sampleOutcome(&dst)
+	var total uint64
+	for _, n := range dropped {
+		total += n
+	}
+	// the 9 logs dropped after the last pass are not reported
+	if len(msgs) != workers*n/10 || total != workers*n-uint64(len(msgs))-9 {
+		t.Fatalf("unexpected outcome: %d passed, %d reported dropped", len(msgs), total)
+	}
+}
@@ -0,0 +1,166 @@
+package log
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/blitz-frost/log/logger"
+)
+
+// A ScrubPattern defines content to be redacted by a ScrubLogger.
+type ScrubPattern struct {
+	Regexp *regexp.Regexp
+	Valid  func(match string) bool // optional; if set, only matches for which it returns true are redacted
+}
+
+// Predefined scrub patterns.
+var (
+	ScrubEmail = ScrubPattern{
+		Regexp: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+	}
+
+	// 13 to 19 digits, optionally grouped by spaces or dashes, starting with a major network prefix (2-6) and passing the Luhn check
+	// this leaves out most timestamps and identifiers
+	ScrubCard = ScrubPattern{
+		Regexp: regexp.MustCompile(`\b[2-6](?:[ \-]?\d){12,18}\b`),
+		Valid:  luhn,
+	}
+
+	// bearer credentials and JWTs
+	ScrubToken = ScrubPattern{
+		Regexp: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*|\beyJ[A-Za-z0-9_\-]*\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`),
+	}
+)
+
+// DefaultScrubPatterns are used by ScrubMake when no patterns are given.
+var DefaultScrubPatterns = []ScrubPattern{ScrubEmail, ScrubCard, ScrubToken}
+
+// A ScrubLogger replaces all pattern matches in log messages and string values with Redacted, before forwarding to its destination.
+// Meant to catch PII that slipped into free-form strings. Subblocks and lists are scrubbed recursively.
+// Errors and fmt.Stringers are scrubbed through their string form, and replaced by the result if anything was redacted. Other value types are left as is.
+//
+// Scrubbing is done at call time and is comparatively expensive, so it must be opted into explicitly.
+//
+// Preformatting scrubs the input before passing it on to the destination's Preformat, so static Node entries are scrubbed only once.
+// EntriesGivers that were preformatted directly by the destination, bypassing the ScrubLogger, are scrubbed again on each log, losing their preformatting.
+type ScrubLogger struct {
+	forwarder
+	patterns []ScrubPattern
+}
+
+// ScrubMake returns a ScrubLogger that forwards to dst. If no patterns are given, DefaultScrubPatterns is used.
+func ScrubMake(dst Logger, patterns ...ScrubPattern) ScrubLogger {
+	if len(patterns) == 0 {
+		patterns = DefaultScrubPatterns
+	}
+	return ScrubLogger{
+		forwarder: forwarder{dst},
+		patterns:  patterns,
+	}
+}
+
+func (x ScrubLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	o := make([]EntriesGiver, len(e))
+	for i, g := range e {
+		if same, ok := g.(scrubEntries); ok {
+			o[i] = same.EntriesGiver
+		} else {
+			o[i] = x.scrub(g)
+		}
+	}
+
+	x.dst.Log(lvl, x.scrubString(msg), o...)
+}
+
+func (x ScrubLogger) Preformat(e EntriesGiver) EntriesGiver {
+	if same, ok := e.(scrubEntries); ok {
+		return same
+	}
+
+	return scrubEntries{x.forwarder.Preformat(x.scrub(e))}
+}
+
+func (x ScrubLogger) scrub(e EntriesGiver) Entries {
+	src := e.Entries()
+	o := make(Entries, len(src))
+	for i, entry := range src {
+		o[i] = Entry{entry.Key, x.scrubValue(entry.Value)}
+	}
+	return o
+}
+
+func (x ScrubLogger) scrubString(s string) string {
+	for _, p := range x.patterns {
+		if p.Valid == nil {
+			s = p.Regexp.ReplaceAllLiteralString(s, Redacted)
+			continue
+		}
+		s = p.Regexp.ReplaceAllStringFunc(s, func(match string) string {
+			if p.Valid(match) {
+				return Redacted
+			}
+			return match
+		})
+	}
+	return s
+}
+
+func (x ScrubLogger) scrubValue(v any) any {
+	switch val := v.(type) {
+	case string:
+		return x.scrubString(val)
+	case EntriesGiver:
+		return x.scrub(val)
+	case logger.List:
+		o := make(logger.List, len(val))
+		for i, elem := range val {
+			o[i] = x.scrubValue(elem)
+		}
+		return o
+	case time.Time, time.Duration, SecretValue:
+		// Stringers that can't hold PII
+		return v
+	case error:
+		return x.scrubText(v, val.Error())
+	case fmt.Stringer:
+		return x.scrubText(v, val.String())
+	}
+	return v
+}
+
+// scrubText returns the scrubbed form of s, if different, or v otherwise
+func (x ScrubLogger) scrubText(v any, s string) any {
+	if o := x.scrubString(s); o != s {
+		return o
+	}
+	return v
+}
+
+// scrubEntries marks EntriesGivers that have already been scrubbed and preformatted by a ScrubLogger.
+type scrubEntries struct {
+	EntriesGiver
+}
+
+// luhn checks the digits of s against the Luhn checksum, ignoring any other characters
+func luhn(s string) bool {
+	sum := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
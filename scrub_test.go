@@ -0,0 +1,77 @@
+package log
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestScrubPatterns(t *testing.T) {
+	x := ScrubMake(nil)
+
+	cases := []struct {
+		in, out string
+	}{
+		{"contact bob.smith+tag@mail.example.co.uk now", "contact [REDACTED] now"},
+		{"card 4111 1111 1111 1111 declined", "card [REDACTED] declined"},
+		{"card 5500-0000-0000-0004", "card [REDACTED]"},
+		{"card 378282246310005", "card [REDACTED]"},
+		{"Authorization: Bearer abc.DEF-123_~+/==", "Authorization: [REDACTED]"},
+		{"jwt eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig-_x end", "jwt [REDACTED] end"},
+
+		// not PII
+		{"ts=1700000000000", "ts=1700000000000"},
+		{"order 1234567890123", "order 1234567890123"},
+		{"card-like 4111 1111 1111 1112", "card-like 4111 1111 1111 1112"}, // fails the Luhn check
+		{"user@localhost", "user@localhost"},
+	}
+	for _, c := range cases {
+		if got := x.scrubString(c.in); got != c.out {
+			t.Errorf("%q: expected %q, got %q", c.in, c.out, got)
+		}
+	}
+}
+
+func TestScrubLogger(t *testing.T) {
+	var dst recorder
+	x := ScrubMake(&dst)
+
+	n := NodeMake(x, Entries{{"owner", "bob@example.com"}})
+	n.Log(Info, "mail bob@example.com",
+		Entries{{"n", 1}, {"sub", Entries{{"mail", "bob@example.com"}}}},
+	)
+	LogError(x, Error, "fail", errors.New("user bob@example.com not found"))
+
+	r := dst.records()
+	if r[0].msg != "mail [REDACTED]" {
+		t.Fatalf("message not scrubbed: %q", r[0].msg)
+	}
+	if v, _ := lookup(r[0].e, "owner"); v != Redacted {
+		t.Fatalf("static entry not scrubbed: %v", v)
+	}
+	if v, _ := lookup(r[0].e, "n"); v != 1 {
+		t.Fatalf("non string value changed: %v", v)
+	}
+	sub, _ := lookup(r[0].e, "sub")
+	if v, _ := lookup(sub.(Entries), "mail"); v != Redacted {
+		t.Fatalf("subblock not scrubbed: %v", sub)
+	}
+	if v, _ := lookup(r[1].e, "err"); v != "user [REDACTED] not found" {
+		t.Fatalf("error not scrubbed: %v", v)
+	}
+}
+
+func TestScrubCustomPattern(t *testing.T) {
+	var dst recorder
+	ip := ScrubPattern{Regexp: regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}\b`)}
+	x := ScrubMake(&dst, ip)
+
+	x.Log(Info, "from 10.0.0.1", Entry{"mail", "bob@example.com"})
+	r := dst.records()[0]
+	if r.msg != "from [REDACTED]" {
+		t.Fatalf("custom pattern not applied: %q", r.msg)
+	}
+	if v, _ := lookup(r.e, "mail"); v != "bob@example.com" {
+		t.Fatalf("default patterns applied along with custom ones: %v", v)
+	}
+}
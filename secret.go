@@ -0,0 +1,37 @@
+package log
+
+import (
+	"fmt"
+	"io"
+)
+
+// Redacted replaces secret values in logs.
+const Redacted = "[REDACTED]"
+
+// A SecretValue is always logged as Redacted, by all backends. Created with Secret.
+type SecretValue struct{}
+
+// Secret marks a value as sensitive at the call site:
+//
+//	Log(Info, "login", Entry{"token", Secret(token)})
+//
+// The value is discarded on the spot, so it cannot leak through any formatting path.
+func Secret(v any) any {
+	return SecretValue{}
+}
+
+func (x SecretValue) Format(f fmt.State, verb rune) {
+	io.WriteString(f, Redacted)
+}
+
+func (x SecretValue) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + Redacted + `"`), nil
+}
+
+func (x SecretValue) MarshalText() ([]byte, error) {
+	return []byte(Redacted), nil
+}
+
+func (x SecretValue) String() string {
+	return Redacted
+}
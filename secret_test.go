@@ -0,0 +1,29 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSecret(t *testing.T) {
+	const token = "hunter2"
+	e := Entries{{"token", Secret(token)}, {"sub", Entries{{"token", Secret(token)}}}}
+
+	line := lineOutput(func(x LineLogger) {
+		x.Log(Info, "login", e)
+		x.Log(Info, "login", x.Preformat(e))
+	})
+	js := jsonOutput(func(x JSONLogger) {
+		x.Log(Info, "login", e)
+		x.Log(Info, "login", x.Preformat(e))
+	})
+
+	for _, out := range []string{line, js} {
+		if strings.Contains(out, token) {
+			t.Fatalf("secret leaked: %q", out)
+		}
+		if n := strings.Count(out, Redacted); n != 4 {
+			t.Fatalf("expected 4 redacted values, got %d: %q", n, out)
+		}
+	}
+}
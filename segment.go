@@ -0,0 +1,222 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SegmentPeriod is the time span covered by a single SegmentWriter file.
+type SegmentPeriod int
+
+const (
+	Hourly SegmentPeriod = iota // segments named 2006-01-02-15.log
+	Daily                       // segments named 2006-01-02.log
+)
+
+func (x SegmentPeriod) duration() time.Duration {
+	if x == Daily {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+func (x SegmentPeriod) layout() string {
+	if x == Daily {
+		return "2006-01-02"
+	}
+	return "2006-01-02-15"
+}
+
+// SegmentSetup configures a SegmentWriter. Only Dir is mandatory.
+type SegmentSetup struct {
+	Dir       string
+	Period    SegmentPeriod
+	Compress  bool             // gzip completed segments (name.log -> name.log.gz)
+	Retention time.Duration    // delete segments that ended longer than this ago; 0 keeps everything
+	Now       func() time.Time // clock used for rotation; defaults to time.Now
+	OnError   func(error)      // handles compression and retention errors, which don't affect written data; nil ignores them
+}
+
+// A SegmentWriter is an append only io.WriteCloser that splits its output into time based segment files, for log archival.
+// Meant to be used as the destination of a LineLogger or JSONLogger.
+//
+// Each Write checks the current time, and rolls over to a new segment when it falls into a different period than the current one.
+// Completed segments are then optionally compressed, and segments past retention are deleted.
+// All of this happens synchronously, inside the rolling Write. Failures are passed to the setup OnError, and do not fail the Write.
+//
+// Compression covers all completed segments in the directory, including ones left uncompressed by previous runs, since the first Write also counts as a rollover.
+//
+// A SegmentWriter is not concurrent safe. This matches the single write goroutine of Loggers built on logger.T, so it should only be used by one such Logger.
+type SegmentWriter struct {
+	setup SegmentSetup
+
+	f    *os.File
+	name string // current segment file name
+}
+
+// SegmentWriterMake returns a SegmentWriter, creating the setup directory if needed.
+// The first segment is opened on the first Write. Existing segments are appended to.
+func SegmentWriterMake(setup SegmentSetup) (*SegmentWriter, error) {
+	if setup.Now == nil {
+		setup.Now = time.Now
+	}
+
+	if err := os.MkdirAll(setup.Dir, 0o755); err != nil {
+		return nil, ErrorMake("segment directory", err)
+	}
+
+	return &SegmentWriter{
+		setup: setup,
+	}, nil
+}
+
+// Close closes the current segment. It is not compressed, since it may be resumed later; if not, it will be compressed on the first rollover of a later run.
+func (x *SegmentWriter) Close() error {
+	if x.f == nil {
+		return nil
+	}
+	err := x.f.Close()
+	x.f = nil
+	return err
+}
+
+func (x *SegmentWriter) Write(b []byte) (int, error) {
+	now := x.setup.Now()
+
+	if name := now.Format(x.setup.Period.layout()) + ".log"; name != x.name {
+		if err := x.rotate(name); err != nil {
+			return 0, err
+		}
+		x.maintain(now)
+	}
+
+	return x.f.Write(b)
+}
+
+// compress gzips all completed segments
+func (x *SegmentWriter) compress() error {
+	entries, err := os.ReadDir(x.setup.Dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		stem, ext, ok := strings.Cut(name, ".")
+		if !ok || ext != "log" || name == x.name || !x.owns(stem) {
+			continue
+		}
+		if err := x.compressFile(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compressFile gzips a completed segment, then removes the original
+func (x *SegmentWriter) compressFile(name string) error {
+	path := filepath.Join(x.setup.Dir, name)
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+
+	w := gzip.NewWriter(dst)
+	if _, err = io.Copy(w, src); err == nil {
+		err = w.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// maintain compresses and prunes segments, as configured, reporting errors to OnError
+func (x *SegmentWriter) maintain(now time.Time) {
+	var err error
+	if x.setup.Compress {
+		err = x.compress()
+	}
+	if err == nil && x.setup.Retention > 0 {
+		err = x.prune(now)
+	}
+
+	if err != nil && x.setup.OnError != nil {
+		x.setup.OnError(ErrorMake("segment maintenance", err))
+	}
+}
+
+// owns checks if stem is a segment name produced by x
+func (x *SegmentWriter) owns(stem string) bool {
+	layout := x.setup.Period.layout()
+	t, err := time.Parse(layout, stem)
+	return err == nil && stem == t.Format(layout)
+}
+
+// prune deletes segments past retention
+func (x *SegmentWriter) prune(now time.Time) error {
+	entries, err := os.ReadDir(x.setup.Dir)
+	if err != nil {
+		return err
+	}
+
+	layout := x.setup.Period.layout()
+	limit := now.Add(-x.setup.Retention)
+	for _, entry := range entries {
+		name := entry.Name()
+		stem, _, ok := strings.Cut(name, ".")
+		if !ok || name == x.name {
+			continue
+		}
+
+		// ignore files that were not created by us
+		start, err := time.ParseInLocation(layout, stem, now.Location())
+		if err != nil || stem != start.Format(layout) {
+			continue
+		}
+
+		if start.Add(x.setup.Period.duration()).Before(limit) {
+			if err := os.Remove(filepath.Join(x.setup.Dir, name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// rotate closes the current segment, if any, and opens a new one
+// on failure to open, the SegmentWriter is left without a current segment, and the next Write retries
+func (x *SegmentWriter) rotate(name string) error {
+	if x.f != nil {
+		x.f.Close() // nothing more to do about it; writes already went through
+		x.f = nil
+		x.name = ""
+	}
+
+	f, err := os.OpenFile(filepath.Join(x.setup.Dir, name), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	x.f = f
+	x.name = name
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// segmentFiles lists the files in dir
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var o []string
+	for _, e := range entries {
+		o = append(o, e.Name())
+	}
+	sort.Strings(o)
+	return o
+}
+
+func TestSegmentWriter(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 2, 14, 59, 0, 0, time.UTC)
+	x, err := SegmentWriterMake(SegmentSetup{
+		Dir:      dir,
+		Compress: true,
+		Now:      func() time.Time { return now },
+		OnError: func(err error) {
+			t.Errorf("maintenance error: %v", err)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x.Write([]byte("a\n"))
+	now = now.Add(30 * time.Second)
+	x.Write([]byte("b\n"))
+	now = now.Add(time.Minute) // crosses into 15:00
+	x.Write([]byte("c\n"))
+	if err := x.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := segmentFiles(t, dir)
+	expected := []string{"2024-01-02-14.log.gz", "2024-01-02-15.log"}
+	if len(files) != 2 || files[0] != expected[0] || files[1] != expected[1] {
+		t.Fatalf("expected %v, got %v", expected, files)
+	}
+
+	f, err := os.Open(filepath.Join(dir, expected[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(r); string(b) != "a\nb\n" {
+		t.Fatalf("unexpected compressed segment: %q", b)
+	}
+
+	if b, _ := os.ReadFile(filepath.Join(dir, expected[1])); string(b) != "c\n" {
+		t.Fatalf("unexpected current segment: %q", b)
+	}
+}
+
+func TestSegmentWriterRetention(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"2024-01-01.log", "2024-01-05.log", "unrelated.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("old\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC)
+	x, err := SegmentWriterMake(SegmentSetup{
+		Dir:       dir,
+		Period:    Daily,
+		Compress:  true,
+		Retention: 48 * time.Hour,
+		Now:       func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.Write([]byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	x.Close()
+
+	// the segment left over from a previous run is compressed, the expired one deleted
+	files := segmentFiles(t, dir)
+	expected := []string{"2024-01-05.log.gz", "2024-01-06.log", "unrelated.log"}
+	if len(files) != 3 || files[0] != expected[0] || files[1] != expected[1] || files[2] != expected[2] {
+		t.Fatalf("expected %v, got %v", expected, files)
+	}
+}
+
+func TestSegmentWriterMaintenanceError(t *testing.T) {
+	dir := t.TempDir()
+	// a directory in place of the compressed file makes compression fail
+	if err := os.WriteFile(filepath.Join(dir, "2024-01-01.log"), []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "2024-01-01.log.gz"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var reported error
+	x, _ := SegmentWriterMake(SegmentSetup{
+		Dir:      dir,
+		Period:   Daily,
+		Compress: true,
+		Now:      func() time.Time { return time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC) },
+		OnError:  func(err error) { reported = err },
+	})
+
+	n, err := x.Write([]byte("data\n"))
+	x.Close()
+	if n != 5 || err != nil {
+		t.Fatalf("write failed: %d, %v", n, err)
+	}
+	if reported == nil {
+		t.Fatal("compression error not reported")
+	}
+}
@@ -0,0 +1,52 @@
+package log
+
+import (
+	"runtime/debug"
+)
+
+// SourceKey is the static Entry key used by LibraryNode to identify the origin of logs.
+const SourceKey = "source"
+
+// LibraryNode returns a Node meant to be embedded by libraries, which marks all its logs with a static source block:
+//
+//	"source": {"name": <name>, "version": <version>}
+//
+// This distinguishes library logs from application logs when both share the same Logger.
+//
+// name should be the library's module path. If version is empty, it is looked up in the build info of the running binary, and omitted if not found.
+func LibraryNode(dst Logger, name, version string) Node {
+	if version == "" {
+		version = moduleVersion(name)
+	}
+
+	src := Entries{{"name", name}}
+	if version != "" {
+		src = append(src, Entry{"version", version})
+	}
+
+	return NodeMake(dst, Entries{{SourceKey, src}})
+}
+
+// moduleVersion returns the version of the named module, as recorded in the build info, or an empty string if unavailable.
+func moduleVersion(path string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	mods := append([]*debug.Module{&info.Main}, info.Deps...)
+	for _, mod := range mods {
+		if mod.Path != path {
+			continue
+		}
+		if mod.Replace != nil {
+			mod = mod.Replace
+		}
+		if mod.Version == "(devel)" {
+			return ""
+		}
+		return mod.Version
+	}
+
+	return ""
+}
@@ -0,0 +1,29 @@
+package log
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLibraryNode(t *testing.T) {
+	var x recorder
+	LibraryNode(&x, "example.com/lib", "v1.2.3").Log(Info, "msg", Entry{"a", 1})
+
+	expected := Entries{
+		{SourceKey, Entries{{"name", "example.com/lib"}, {"version", "v1.2.3"}}},
+		{"a", 1},
+	}
+	if e := x.records()[0].e; !reflect.DeepEqual(e, expected) {
+		t.Fatalf("expected %v, got %v", expected, e)
+	}
+}
+
+func TestLibraryNodeUnknownVersion(t *testing.T) {
+	var x recorder
+	LibraryNode(&x, "example.com/unknown", "").Log(Info, "msg")
+
+	expected := Entries{{SourceKey, Entries{{"name", "example.com/unknown"}}}}
+	if e := x.records()[0].e; !reflect.DeepEqual(e, expected) {
+		t.Fatalf("expected %v, got %v", expected, e)
+	}
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// SummaryKey is the key of the block logged by IntervalSummaryLogger.
+const SummaryKey = "summary"
+
+// An IntervalSummaryLogger aggregates named counters, and periodically logs them as a single {SummaryKey, {name: count, ...}} block, before resetting them.
+// Meant for cheap progress reporting of long running jobs, instead of per item logs.
+//
+// Counters appear in the order they were first incremented. Once a counter is known, it is included in every summary, even if it stays at 0.
+// Nothing is logged while no counters are known.
+//
+// Regular Log calls are forwarded to the destination as is. Concurrent safe.
+type IntervalSummaryLogger struct {
+	forwarder
+	setup IntervalSummarySetup
+
+	mux    sync.Mutex
+	names  []string
+	counts map[string]uint64
+
+	stop func()
+	quit chan struct{}
+	done chan struct{}
+}
+
+// IntervalSummarySetup configures an IntervalSummaryLogger. Only Interval is mandatory.
+type IntervalSummarySetup struct {
+	Interval time.Duration
+	Level    int    // summary log level; Default is used as is
+	Message  string // summary log message; defaults to "summary"
+
+	// creates the summary clock; defaults to a time.Ticker
+	// useful for testing, or for aligning summaries with an external clock
+	Ticker func(time.Duration) (<-chan time.Time, func())
+}
+
+// IntervalSummaryMake returns a running IntervalSummaryLogger that forwards to dst.
+// It must be closed in order to stop the summary goroutine.
+func IntervalSummaryMake(dst Logger, setup IntervalSummarySetup) *IntervalSummaryLogger {
+	if setup.Message == "" {
+		setup.Message = "summary"
+	}
+	if setup.Ticker == nil {
+		setup.Ticker = func(d time.Duration) (<-chan time.Time, func()) {
+			t := time.NewTicker(d)
+			return t.C, t.Stop
+		}
+	}
+
+	x := &IntervalSummaryLogger{
+		forwarder: forwarder{dst},
+		setup:     setup,
+		counts:    make(map[string]uint64),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	var tick <-chan time.Time
+	tick, x.stop = setup.Ticker(setup.Interval)
+	go x.run(tick)
+
+	return x
+}
+
+// Add increases a counter by n.
+func (x *IntervalSummaryLogger) Add(name string, n uint64) {
+	x.mux.Lock()
+	defer x.mux.Unlock()
+
+	if _, ok := x.counts[name]; !ok {
+		x.names = append(x.names, name)
+	}
+	x.counts[name] += n
+}
+
+// Close stops the summary goroutine, logs a final summary of the current counters, then closes the destination, if it is a Closer.
+func (x *IntervalSummaryLogger) Close() {
+	close(x.quit)
+	<-x.done
+
+	x.emit()
+
+	x.forwarder.Close()
+}
+
+// Inc increases a counter by 1.
+func (x *IntervalSummaryLogger) Inc(name string) {
+	x.Add(name, 1)
+}
+
+func (x *IntervalSummaryLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	x.dst.Log(lvl, msg, e...)
+}
+
+// emit logs and resets the counters
+func (x *IntervalSummaryLogger) emit() {
+	x.mux.Lock()
+	if len(x.names) == 0 {
+		x.mux.Unlock()
+		return
+	}
+
+	block := make(Entries, len(x.names))
+	for i, name := range x.names {
+		block[i] = Entry{name, x.counts[name]}
+		x.counts[name] = 0
+	}
+	x.mux.Unlock()
+
+	x.dst.Log(x.setup.Level, x.setup.Message, Entry{SummaryKey, block})
+}
+
+func (x *IntervalSummaryLogger) run(tick <-chan time.Time) {
+	defer close(x.done)
+	defer x.stop()
+
+	for {
+		select {
+		case <-tick:
+			x.emit()
+		case <-x.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+// chanLogger passes gathered logs through a channel
+type chanLogger chan record
+
+func (x chanLogger) Log(lvl int, msg string, e ...EntriesGiver) {
+	var o Entries
+	for _, g := range e {
+		o = append(o, g.Entries()...)
+	}
+	x <- record{lvl, msg, o}
+}
+
+func TestIntervalSummaryLogger(t *testing.T) {
+	tick := make(chan time.Time)
+	stopped := false
+	dst := make(chanLogger, 8)
+	x := IntervalSummaryMake(dst, IntervalSummarySetup{
+		Interval: time.Minute,
+		Level:    Info,
+		Ticker: func(d time.Duration) (<-chan time.Time, func()) {
+			if d != time.Minute {
+				t.Errorf("unexpected interval %v", d)
+			}
+			return tick, func() { stopped = true }
+		},
+	})
+
+	summary := func() Entries {
+		t.Helper()
+		select {
+		case r := <-dst:
+			if r.lvl != Info || r.msg != "summary" {
+				t.Fatalf("unexpected summary log: %v", r)
+			}
+			v, _ := lookup(r.e, SummaryKey)
+			return v.(Entries)
+		case <-time.After(time.Second):
+			t.Fatal("no summary logged")
+			return nil
+		}
+	}
+
+	x.Inc("items")
+	x.Inc("items")
+	x.Add("errors", 3)
+	tick <- time.Now()
+	if s := summary(); !entriesEqual(s, Entries{{"items", uint64(2)}, {"errors", uint64(3)}}) {
+		t.Fatalf("unexpected first summary: %v", s)
+	}
+
+	x.Inc("errors")
+	tick <- time.Now()
+	if s := summary(); !entriesEqual(s, Entries{{"items", uint64(0)}, {"errors", uint64(1)}}) {
+		t.Fatalf("counters not reset: %v", s)
+	}
+
+	x.Inc("items")
+	x.Close()
+	if s := summary(); !entriesEqual(s, Entries{{"items", uint64(1)}, {"errors", uint64(0)}}) {
+		t.Fatalf("unexpected final summary: %v", s)
+	}
+	if !stopped {
+		t.Fatal("ticker not stopped")
+	}
+}
@@ -0,0 +1,95 @@
+package log
+
+import (
+	"sync/atomic"
+
+	"github.com/blitz-frost/log/logger"
+)
+
+// currently suppressed keys; nil if none
+var suppressedKeys atomic.Pointer[map[string]struct{}]
+
+// SetSuppressedKeys replaces the set of Entry keys that are dropped from logs during formatting, at any depth.
+// Calling it without arguments disables suppression.
+//
+// Meant for disabling noisy or expensive fields at runtime, without code changes; the package has no configuration mechanism of its own, so it should be wired to whatever the application uses.
+// Concurrent safe. Takes effect for all logs formatted after the call, including ones that are already queued.
+//
+// All backends in this module honor the set. Custom backends should pass their Entries through FilterSuppressed.
+func SetSuppressedKeys(keys ...string) {
+	if len(keys) == 0 {
+		suppressedKeys.Store(nil)
+		return
+	}
+
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+	suppressedKeys.Store(&set)
+}
+
+// FilterSuppressed returns e without the Entries whose keys are currently suppressed, searching subblocks and lists recursively. See SetSuppressedKeys.
+// e itself is returned if there is nothing to drop, so preformatted subblocks are only discarded if they actually contain suppressed keys.
+func FilterSuppressed(e Entries) Entries {
+	set := suppressedKeys.Load()
+	if set == nil {
+		return e
+	}
+
+	o, _ := filterEntries(e, *set)
+	return o
+}
+
+// filterEntries returns e without the keys in set, and whether anything was dropped
+// a new slice is only allocated if needed
+func filterEntries(e Entries, set map[string]struct{}) (Entries, bool) {
+	var o Entries
+	for i, entry := range e {
+		_, drop := set[entry.Key]
+		v, changed := entry.Value, false
+		if !drop {
+			v, changed = filterValue(v, set)
+		}
+
+		if o == nil && (drop || changed) {
+			// first difference; copy everything so far
+			o = make(Entries, i, len(e))
+			copy(o, e[:i])
+		}
+		if o != nil && !drop {
+			o = append(o, Entry{entry.Key, v})
+		}
+	}
+
+	if o == nil {
+		return e, false
+	}
+	return o, true
+}
+
+// filterValue returns v without the keys in set, and whether anything was dropped
+func filterValue(v any, set map[string]struct{}) (any, bool) {
+	switch val := v.(type) {
+	case EntriesGiver:
+		if sub, changed := filterEntries(val.Entries(), set); changed {
+			return sub, true
+		}
+	case logger.List:
+		var o logger.List
+		for i, elem := range val {
+			sub, changed := filterValue(elem, set)
+			if o == nil && changed {
+				o = make(logger.List, len(val))
+				copy(o, val[:i])
+			}
+			if o != nil {
+				o[i] = sub
+			}
+		}
+		if o != nil {
+			return o, true
+		}
+	}
+	return v, false
+}
@@ -0,0 +1,68 @@
+package log
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blitz-frost/log/logger"
+)
+
+func TestFilterSuppressed(t *testing.T) {
+	defer SetSuppressedKeys()
+
+	e := Entries{
+		{"noisy", 1},
+		{"a", 2},
+		{"sub", Entries{{"noisy", 3}, {"b", 4}}},
+		{"list", logger.List{Entries{{"noisy", 5}}, 6}},
+	}
+
+	if o := FilterSuppressed(e); &o[0] != &e[0] {
+		t.Fatal("Entries copied without suppression")
+	}
+
+	SetSuppressedKeys("noisy")
+	expected := Entries{
+		{"a", 2},
+		{"sub", Entries{{"b", 4}}},
+		{"list", logger.List{Entries{}, 6}},
+	}
+	if o := FilterSuppressed(e); !reflect.DeepEqual(o, expected) {
+		t.Fatalf("expected %v, got %v", expected, o)
+	}
+	if e[0].Key != "noisy" || len(e[2].Value.(Entries)) != 2 {
+		t.Fatal("input modified")
+	}
+
+	SetSuppressedKeys()
+	if o := FilterSuppressed(e); !reflect.DeepEqual(o, e) {
+		t.Fatalf("suppression not disabled: %v", o)
+	}
+}
+
+func TestSuppressedKeysToggle(t *testing.T) {
+	defer SetSuppressedKeys()
+
+	logOnce := func() string {
+		return lineOutput(func(x LineLogger) {
+			n := NodeMake(x, Entries{{"static", Entries{{"noisy", 1}, {"keep", 2}}}})
+			n.Log(Info, "msg", Entry{"noisy", 3})
+		})
+	}
+
+	const full = "INFO  msg\nstatic\n  noisy - 1\n  keep - 2\nnoisy - 3\n\n"
+	if out := logOnce(); out != full {
+		t.Fatalf("expected %q, got %q", full, out)
+	}
+
+	SetSuppressedKeys("noisy")
+	const suppressed = "INFO  msg\nstatic\n  keep - 2\n\n"
+	if out := logOnce(); out != suppressed {
+		t.Fatalf("expected %q, got %q", suppressed, out)
+	}
+
+	SetSuppressedKeys()
+	if out := logOnce(); out != full {
+		t.Fatalf("expected %q after disabling, got %q", full, out)
+	}
+}
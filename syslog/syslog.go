@@ -0,0 +1,238 @@
+//go:build !windows && !plan9
+
+// Package syslog provides a Logger that writes to a syslog daemon (journald, rsyslog, etc.).
+package syslog
+
+import (
+	"fmt"
+	stdsyslog "log/syslog"
+	"strconv"
+	"strings"
+
+	"github.com/blitz-frost/log"
+	"github.com/blitz-frost/log/logger"
+)
+
+// Logger wraps a syslog.Writer. Values must be created using LoggerMake or LoggerOf.
+//
+// Logs are written as single lines, with the entry block following the message:
+//
+//	msg key0=value0 key1="value 1" key2={subkey0=subvalue0 subkey1=subvalue1}
+//
+// logger.List values are written as [elem0 elem1].
+// Values containing spaces, quotes, brackets or '=' are quoted.
+type Logger struct {
+	logger.T[message]
+
+	core *core
+}
+
+// LoggerMake creates a Logger value. It is a shorthand for syslog.Dial -> LoggerOf, using the LOG_USER facility.
+// See https://pkg.go.dev/log/syslog#Dial for the meaning of the parameters.
+//
+// opts are passed on to logger.Make.
+func LoggerMake(network, raddr, tag string, opts ...logger.Option) (Logger, error) {
+	w, err := stdsyslog.Dial(network, raddr, stdsyslog.LOG_INFO|stdsyslog.LOG_USER, tag)
+	if err != nil {
+		return Logger{}, log.ErrorMake("syslog dial", err)
+	}
+
+	return LoggerOf(w, opts...), nil
+}
+
+// LoggerOf wraps a syslog.Writer. Useful for custom setups.
+// The Writer will be closed when the Logger is closed.
+//
+// opts are passed on to logger.Make.
+func LoggerOf(w *stdsyslog.Writer, opts ...logger.Option) Logger {
+	c := &core{
+		w: w,
+	}
+	return Logger{
+		T:    logger.Make[message](c, opts...),
+		core: c,
+	}
+}
+
+func (x Logger) Preformat(e log.EntriesGiver) log.EntriesGiver {
+	return entriesMake(e)
+}
+
+// SetOnError sets a function to handle write and close errors, instead of panicking.
+// f will usually be called from the write goroutine; it must not block indefinitely, or log to the same Logger.
+//
+// Should be called before the Logger is put to use.
+func (x Logger) SetOnError(f func(error)) {
+	x.core.onError = f
+}
+
+type buffer []byte
+
+func (x *buffer) append(e log.EntriesGiver) {
+	// check for preformatted entries
+	if pre, ok := e.(entries); ok {
+		if len(pre.buf) > 0 {
+			x.separate()
+			*x = append(*x, pre.buf...)
+		}
+		return
+	}
+
+	for _, entry := range e.Entries() {
+		x.appendEntry(entry)
+	}
+}
+
+func (x *buffer) appendEntry(e log.Entry) {
+	x.separate()
+	x.appendString(e.Key)
+	*x = append(*x, '=')
+	x.appendValue(e.Value)
+}
+
+// appendString appends s, quoting it if necessary.
+func (x *buffer) appendString(s string) {
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"={}[]") {
+		*x = strconv.AppendQuote(*x, s)
+		return
+	}
+	*x = append(*x, s...)
+}
+
+func (x *buffer) appendValue(v any) {
+	switch sub := v.(type) {
+	case log.EntriesGiver:
+		// nested blocks are rendered inline
+		*x = append(*x, '{')
+		x.append(sub)
+		*x = append(*x, '}')
+	case logger.List:
+		*x = append(*x, '[')
+		for i, elem := range sub {
+			if i > 0 {
+				*x = append(*x, ' ')
+			}
+			x.appendValue(elem)
+		}
+		*x = append(*x, ']')
+	case error:
+		x.appendString(sub.Error())
+	case string:
+		x.appendString(sub)
+	default:
+		x.appendString(fmt.Sprint(sub))
+	}
+}
+
+// separate inserts a space before a new member, unless at the start of the buffer or of a block
+func (x *buffer) separate() {
+	if n := len(*x); n > 0 && (*x)[n-1] != '{' {
+		*x = append(*x, ' ')
+	}
+}
+
+type core struct {
+	w       *stdsyslog.Writer
+	onError logger.ErrorHandler
+}
+
+func (x *core) Close() {
+	if err := x.w.Close(); err != nil {
+		x.onError.Handle(err)
+	}
+}
+
+func (x *core) Format(data logger.Data) message {
+	// map level to syslog priority
+	var pri stdsyslog.Priority
+	switch data.Level {
+	case log.Debug:
+		pri = stdsyslog.LOG_DEBUG
+	case log.Notice:
+		pri = stdsyslog.LOG_NOTICE
+	case log.Warning:
+		pri = stdsyslog.LOG_WARNING
+	case log.Error:
+		pri = stdsyslog.LOG_ERR
+	case log.Critical:
+		pri = stdsyslog.LOG_CRIT
+	case log.Alert:
+		pri = stdsyslog.LOG_ALERT
+	case log.Emergency:
+		pri = stdsyslog.LOG_EMERG
+	default:
+		// Default, Info and custom levels
+		pri = stdsyslog.LOG_INFO
+	}
+
+	buf := make(buffer, 0, 1024)
+	buf = append(buf, data.Message...)
+	for _, e := range data.Entries {
+		buf.append(log.FilterSuppressed(e))
+	}
+
+	return message{
+		priority: pri,
+		text:     string(buf),
+	}
+}
+
+func (x *core) Write(m message) {
+	var err error
+	switch m.priority {
+	case stdsyslog.LOG_DEBUG:
+		err = x.w.Debug(m.text)
+	case stdsyslog.LOG_NOTICE:
+		err = x.w.Notice(m.text)
+	case stdsyslog.LOG_WARNING:
+		err = x.w.Warning(m.text)
+	case stdsyslog.LOG_ERR:
+		err = x.w.Err(m.text)
+	case stdsyslog.LOG_CRIT:
+		err = x.w.Crit(m.text)
+	case stdsyslog.LOG_ALERT:
+		err = x.w.Alert(m.text)
+	case stdsyslog.LOG_EMERG:
+		err = x.w.Emerg(m.text)
+	default:
+		err = x.w.Info(m.text)
+	}
+
+	if err != nil {
+		x.onError.Handle(err)
+	}
+}
+
+// entries is the optimized preformatted entries for Logger.
+type entries struct {
+	src log.Entries
+
+	buf buffer // holds space separated members
+}
+
+func entriesMake(src log.EntriesGiver) entries {
+	if same, ok := src.(entries); ok {
+		return same
+	}
+
+	var buf buffer
+	e := src.Entries()
+	for _, entry := range e {
+		buf.appendEntry(entry)
+	}
+
+	return entries{
+		src: e,
+		buf: buf,
+	}
+}
+
+func (x entries) Entries() log.Entries {
+	return x.src
+}
+
+// message is the raw log format.
+type message struct {
+	priority stdsyslog.Priority
+	text     string
+}
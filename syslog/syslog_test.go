@@ -0,0 +1,84 @@
+//go:build !windows && !plan9
+
+package syslog
+
+import (
+	stdsyslog "log/syslog"
+	"strconv"
+	"testing"
+
+	"github.com/blitz-frost/log"
+	"github.com/blitz-frost/log/logger"
+)
+
+// format formats a log with a bare core
+func format(lvl int, e ...log.Entries) message {
+	c := &core{}
+	return c.Format(logger.Data{
+		Level:   lvl,
+		Message: "msg",
+		Entries: e,
+	})
+}
+
+func TestPriority(t *testing.T) {
+	cases := []struct {
+		lvl int
+		pri stdsyslog.Priority
+	}{
+		{log.Default, stdsyslog.LOG_INFO},
+		{log.Debug, stdsyslog.LOG_DEBUG},
+		{log.Info, stdsyslog.LOG_INFO},
+		{log.Notice, stdsyslog.LOG_NOTICE},
+		{log.Warning, stdsyslog.LOG_WARNING},
+		{log.Error, stdsyslog.LOG_ERR},
+		{log.Critical, stdsyslog.LOG_CRIT},
+		{log.Alert, stdsyslog.LOG_ALERT},
+		{log.Emergency, stdsyslog.LOG_EMERG},
+		{log.Emergency + 100, stdsyslog.LOG_INFO}, // custom level
+	}
+	for _, c := range cases {
+		if m := format(c.lvl); m.priority != c.pri {
+			t.Errorf("level %d: expected priority %d, got %d", c.lvl, c.pri, m.priority)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	m := format(log.Info, log.Entries{
+		{"a", 1},
+		{"quoted", "two words"},
+		{"eq", "k=v"},
+		{"empty", ""},
+		{"sub", log.Entries{{"b", "x"}, {"c", "y z"}}},
+		{"list", logger.List{1, "two words", log.Entries{{"k", "v"}}}},
+	}, log.Entries{{"next", true}})
+
+	const expected = `msg a=1 quoted="two words" eq="k=v" empty="" sub={b=x c="y z"} list=[1 "two words" {k=v}] next=true`
+	if m.text != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, m.text)
+	}
+}
+
+func TestPreformat(t *testing.T) {
+	sub := log.Entries{{"k", "v w"}, {"n", 2}}
+	raw := format(log.Info, log.Entries{{"sub", sub}})
+	pre := format(log.Info, log.Entries{{"sub", entriesMake(sub)}})
+
+	if raw.text != pre.text {
+		t.Fatalf("preformatted output %q differs from %q", pre.text, raw.text)
+	}
+
+	// empty preformatted blocks must not leave stray spaces
+	m := format(log.Info, log.Entries{{"sub", entriesMake(log.Entries{})}, {"a", 1}})
+	if m.text != "msg sub={} a=1" {
+		t.Fatalf("unexpected output: %q", m.text)
+	}
+}
+
+func TestSecret(t *testing.T) {
+	m := format(log.Info, log.Entries{{"token", log.Secret("hunter2")}})
+	if m.text != "msg token="+strconv.Quote(log.Redacted) {
+		t.Fatalf("unexpected output: %q", m.text)
+	}
+}